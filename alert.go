@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─── SIGNAL ALERTS ───────────────────────────────────────────────────────────
+// 'keke signal ... --alert you@example.com' emails signals whose confidence
+// is above --alert-threshold through the /send-alert endpoint. Alerts that
+// cannot be sent are queued in ~/.keke/pending-alerts.json and retried on the
+// next 'keke signal' run
+
+const (
+	defaultAlertThreshold = 75
+	maxAlertAttempts      = 5 // queued alerts are dropped after this many tries
+)
+
+// alertOptions - the --alert and --alert-threshold flags
+type alertOptions struct {
+	Email     string
+	Threshold int
+}
+
+// parseAlertFlag handles --alert and --alert-threshold at args[i], returning
+// how many arguments it consumed (0 when args[i] is not an alert flag)
+func parseAlertFlag(args []string, i int, alert *alertOptions) (int, error) {
+	switch args[i] {
+	case "--alert":
+		if i+1 >= len(args) || !strings.Contains(args[i+1], "@") {
+			return 0, fmt.Errorf("--alert needs an email address")
+		}
+		alert.Email = args[i+1]
+		return 2, nil
+	case "--alert-threshold":
+		if i+1 >= len(args) {
+			return 0, fmt.Errorf("--alert-threshold needs a confidence percentage")
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(args[i+1], "%"))
+		if err != nil || n < 0 || n > 100 {
+			return 0, fmt.Errorf("invalid --alert-threshold: %s (0-100)", args[i+1])
+		}
+		alert.Threshold = n
+		return 2, nil
+	}
+	return 0, nil
+}
+
+// AlertSettings - optional SMTP server for alert emails, kept in
+// ~/.keke/alerts.json. Without a host the backend's own mail server is used
+type AlertSettings struct {
+	SMTPHost     string `json:"smtp_host,omitempty"`
+	SMTPPort     int    `json:"smtp_port,omitempty"`
+	SMTPUser     string `json:"smtp_user,omitempty"`
+	SMTPPassword string `json:"smtp_password,omitempty"`
+	From         string `json:"from,omitempty"`
+}
+
+// PendingAlert - an alert waiting to be sent again
+type PendingAlert struct {
+	Email    string      `json:"email"`
+	Signal   TradeSignal `json:"signal"`
+	QueuedAt time.Time   `json:"queued_at"`
+	Attempts int         `json:"attempts"`
+}
+
+// loadAlertSettings reads the SMTP settings, running the setup wizard the
+// first time alerts are used
+func loadAlertSettings() (*AlertSettings, error) {
+	data, err := os.ReadFile(globalAlertSettingsFile())
+	if os.IsNotExist(err) {
+		return setupAlerts()
+	}
+	if err != nil {
+		return nil, err
+	}
+	var settings AlertSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", globalAlertSettingsFile(), err)
+	}
+	return &settings, nil
+}
+
+// setupAlerts asks once how alert emails should be sent and saves the answer
+func setupAlerts() (*AlertSettings, error) {
+	settings := &AlertSettings{}
+	if !isTerminal(os.Stdin) {
+		return settings, nil // nobody to ask: use the backend's mail server
+	}
+
+	fmt.Println()
+	logInfo("Alert email setup (first use)")
+	logInfo("Press Enter to send through Keke's mail server, or give your own SMTP server")
+	settings.SMTPHost = prompt("SMTP host:")
+	if settings.SMTPHost != "" {
+		port, err := strconv.Atoi(prompt("SMTP port [587]:"))
+		if err != nil || port <= 0 {
+			port = 587
+		}
+		settings.SMTPPort = port
+		settings.SMTPUser = prompt("SMTP username:")
+		settings.SMTPPassword = promptPassword("SMTP password")
+		settings.From = prompt("From address:")
+	}
+
+	if err := os.MkdirAll(globalDir(), 0700); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(globalAlertSettingsFile(), data, 0600); err != nil {
+		return nil, err
+	}
+	logSuccess(fmt.Sprintf("Saved alert settings to %s", globalAlertSettingsFile()))
+	return settings, nil
+}
+
+// sendSignalAlerts emails every signal above the threshold, queueing the
+// ones that cannot be sent now
+func sendSignalAlerts(signals []*TradeSignal, alert *alertOptions, auth *AuthData) {
+	if alert == nil || alert.Email == "" {
+		return
+	}
+
+	settings, err := loadAlertSettings()
+	if err != nil {
+		logWarning(fmt.Sprintf("Alert settings: %v", err))
+		settings = &AlertSettings{}
+	}
+
+	var queued []PendingAlert
+	for _, signal := range signals {
+		if signal.Confidence <= alert.Threshold {
+			continue
+		}
+		if err := postAlert(alert.Email, signal, settings, auth); err != nil {
+			logWarning(fmt.Sprintf("Alert for %s not sent (%v), will retry on the next run", signal.Pair, err))
+			queued = append(queued, PendingAlert{Email: alert.Email, Signal: *signal, QueuedAt: time.Now(), Attempts: 1})
+			continue
+		}
+		if !jsonMode {
+			logSuccess(fmt.Sprintf("Alert sent to %s: %s %s (%d%%)", alert.Email, signal.Pair, signal.Direction, signal.Confidence))
+		}
+	}
+
+	if len(queued) > 0 {
+		pending, _ := readPendingAlerts()
+		if err := writePendingAlerts(append(pending, queued...)); err != nil {
+			logWarning(fmt.Sprintf("Failed to queue alerts: %v", err))
+		}
+	}
+}
+
+// retryPendingAlerts sends alerts queued by earlier runs
+func retryPendingAlerts(auth *AuthData) {
+	pending, err := readPendingAlerts()
+	if err != nil {
+		logWarning(fmt.Sprintf("Failed to read pending alerts: %v", err))
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	settings, err := loadAlertSettings()
+	if err != nil {
+		settings = &AlertSettings{}
+	}
+
+	var remaining []PendingAlert
+	sent := 0
+	for _, p := range pending {
+		signal := p.Signal
+		if err := postAlert(p.Email, &signal, settings, auth); err != nil {
+			p.Attempts++
+			if p.Attempts >= maxAlertAttempts {
+				logWarning(fmt.Sprintf("Dropped %s alert for %s after %d attempts: %v", signal.Pair, p.Email, p.Attempts, err))
+				continue
+			}
+			remaining = append(remaining, p)
+			continue
+		}
+		sent++
+	}
+
+	if err := writePendingAlerts(remaining); err != nil {
+		logWarning(fmt.Sprintf("Failed to update pending alerts: %v", err))
+	}
+	if sent > 0 && !jsonMode {
+		logSuccess(fmt.Sprintf("Sent %d queued alerts", sent))
+	}
+	if len(remaining) > 0 && !jsonMode {
+		logWarning(fmt.Sprintf("%d alerts still queued in %s", len(remaining), globalPendingAlertsFile()))
+	}
+}
+
+// postAlert asks the backend to email signal to email
+func postAlert(email string, signal *TradeSignal, settings *AlertSettings, auth *AuthData) error {
+	payload := map[string]interface{}{
+		"email":  email,
+		"signal": signal,
+	}
+	if settings.SMTPHost != "" {
+		payload["smtp"] = settings
+	}
+
+	jsonData, _ := json.Marshal(payload)
+	resp, err := makeAuthenticatedRequestWithRetry("POST", apiEndpoint(EndpointAlert), bytes.NewBuffer(jsonData), auth)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server error %d: %s", resp.StatusCode, redactBody(body))
+	}
+	return nil
+}
+
+func readPendingAlerts() ([]PendingAlert, error) {
+	data, err := os.ReadFile(globalPendingAlertsFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var pending []PendingAlert
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("corrupt %s: %v", globalPendingAlertsFile(), err)
+	}
+	return pending, nil
+}
+
+// writePendingAlerts saves the queue, removing the file once it is empty
+func writePendingAlerts(pending []PendingAlert) error {
+	if len(pending) == 0 {
+		err := os.Remove(globalPendingAlertsFile())
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(globalDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(globalPendingAlertsFile(), data, 0600)
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,14 +18,24 @@ import (
 // ─── ASK (LAM - Large Action Model) ──────────────────────────────────────────
 // AI can READ workspace, WRITE files, and EXECUTE commands
 
+// Show a diff and ask before every file write (disabled with --no-diff)
+var showDiffPreview = true
+
+// Offer the git_commit action to the AI (disabled with --no-git)
+var gitCommitEnabled = true
+
+// Read-only run outside a Keke project (--no-project): the AI may read and
+// list files but not write, run or commit, since there are no snapshots to
+// undo with, and permission grants are not saved
+var readOnlyMode = false
+
+// Actions refused in read-only mode
+var writeActions = []string{"write_file", "execute_command", "git_commit"}
+
 func handleAsk(args []string) {
 	if !isLoggedIn() {
 		logError("Not logged in. Run 'keke login'")
-		return
-	}
-
-	if !isProjectInitialized() {
-		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotLoggedIn
 		return
 	}
 
@@ -32,29 +45,24 @@ func handleAsk(args []string) {
 		logInfo("  keke ask \"add a login page\"")
 		logInfo("  keke ask \"fix the bug in auth.go\"")
 		logInfo("  keke ask \"run tests and fix any failures\"")
+		logInfo("  keke ask --use-plan \"go ahead\"")
+		logInfo("  keke ask --interactive")
+		logInfo("  keke ask --no-project \"what does this script do?\"")
 		return
 	}
 
-	// Parse flags
-	model := "smart" // default
-	var promptParts []string
-
-	for _, arg := range args {
-		switch arg {
-		case "--fast":
-			model = "fast"
-		case "--smart":
-			model = "smart"
-		case "--deep":
-			model = "deep"
-		default:
-			promptParts = append(promptParts, arg)
-		}
+	opts, err := parseAskFlags(args)
+	if err != nil {
+		logError(err.Error())
+		return
 	}
 
-	prompt := strings.Join(promptParts, " ")
-	if prompt == "" {
-		logError("No prompt provided")
+	if opts.NoProject {
+		readOnlyMode = true
+		logInfo("Read-only mode: the AI can read files but not change anything")
+	} else if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init', or use --no-project for a read-only answer")
+		exitCode = exitNotInitialized
 		return
 	}
 
@@ -64,17 +72,255 @@ func handleAsk(args []string) {
 		return
 	}
 
-	logInfo("AI analyzing workspace...")
+	logInfo(fmt.Sprintf("AI analyzing workspace (%s)...", modelLabel(opts.Model)))
 
 	// Start conversation loop with AI
-	conversationLoop(prompt, model, auth)
+	session, err := openSession("ask", opts)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	if opts.UsePlan {
+		plan, err := loadPlan()
+		if err != nil {
+			logError(err.Error())
+			return
+		}
+		logInfo(fmt.Sprintf("Following saved plan (%d steps): %s", len(plan.Steps), truncateText(plan.Prompt, 50)))
+		session.History = append(session.History, map[string]string{
+			"role":    "user",
+			"content": planContext(plan),
+		})
+	}
+
+	if err := attachFiles(session, opts.Files, opts.Model, auth); err != nil {
+		logError(err.Error())
+		return
+	}
+
+	if opts.Prompt != "" {
+		conversationLoop(session, opts.Prompt, opts.Model, auth)
+	}
+	if opts.Interactive {
+		runRepl(session, opts.Model, auth)
+	}
+}
+
+// ─── ATTACHED FILES ──────────────────────────────────────────────────────────
+// --file <path|glob> puts files in the conversation up front, one user turn
+// each. Files too big to send whole are summarized by a separate AI call
+
+const (
+	maxAttachBytes       = 100 * 1024 // larger files are summarized
+	maxSummaryInputBytes = 400 * 1024 // what the summary call gets to see
+)
+
+func attachFiles(session *SessionData, patterns []string, model string, auth *AuthData) error {
+	for _, pattern := range patterns {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid --file pattern %s: %v", pattern, err)
+		}
+		if len(paths) == 0 {
+			if strings.ContainsAny(pattern, "*?[") {
+				return fmt.Errorf("--file %s matches no files", pattern)
+			}
+			paths = []string{pattern} // let the read report what is wrong
+		}
+
+		for _, path := range paths {
+			content := handleReadFile(Action{Type: "read_file", Path: path})
+			if actionFailed(content) {
+				return fmt.Errorf("cannot attach %s: %s", path, content)
+			}
+
+			message := fmt.Sprintf("Here is the content of %s:\n%s", path, content)
+			if len(content) > maxAttachBytes {
+				summary, err := summarizeFile(path, content, model, auth)
+				if err != nil {
+					return fmt.Errorf("cannot summarize %s: %v", path, err)
+				}
+				message = fmt.Sprintf("Here is a summary of %s (%s, too large to include):\n%s",
+					path, formatBytes(int64(len(content))), summary)
+			}
+
+			session.History = append(session.History, map[string]string{
+				"role":    "user",
+				"content": message,
+			})
+		}
+	}
+	return nil
+}
+
+// summarizeFile asks the AI for a summary of a file too large to attach
+func summarizeFile(path, content, model string, auth *AuthData) (string, error) {
+	logInfo(fmt.Sprintf("Summarizing %s (%s)...", path, formatBytes(int64(len(content)))))
+	if len(content) > maxSummaryInputBytes {
+		content = content[:maxSummaryInputBytes] + "\n...[truncated]"
+	}
+
+	payload := map[string]interface{}{
+		"conversation": []map[string]string{
+			{"role": "user", "content": fmt.Sprintf("Summarize this file for a developer who will ask questions about it. "+
+				"List its purpose, main types and functions with their line ranges, and anything unusual.\n\nFile: %s\n%s", path, content)},
+		},
+		"model": model,
+		"mode":  "explain", // no actions
+	}
+
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		return "", err
+	}
+	return response.Message, nil
+}
+
+// askOptions - flags shared by 'keke ask' and 'keke research'
+type askOptions struct {
+	Model       string
+	Prompt      string
+	SessionID   string   // --session: resume a saved conversation
+	Continue    bool     // --continue: resume this project's active session
+	Files       []string // --file: paths or globs to include up front
+	UsePlan     bool     // --use-plan: follow .keke/last-plan.json
+	Interactive bool     // --interactive: keep prompting after the first answer
+	NoProject   bool     // --no-project: read-only, no 'keke init' needed
+}
+
+// AI provider for this run (global --provider), overriding default_provider.
+// It applies to every command that calls the AI, signals included
+var providerFlag string
+
+// aiProvider names the provider requests ask for; empty lets the server pick
+func aiProvider() string {
+	if providerFlag != "" {
+		return providerFlag
+	}
+	return getConfig().DefaultProvider
+}
+
+// modelLabel describes the model and provider in use, e.g. "smart, anthropic"
+func modelLabel(model string) string {
+	if provider := aiProvider(); provider != "" {
+		return model + ", " + provider
+	}
+	return model
+}
+
+// parseAskFlags splits args into flags and the prompt text
+func parseAskFlags(args []string) (*askOptions, error) {
+	opts := &askOptions{Model: getConfig().DefaultModel}
+	var promptParts []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--fast":
+			opts.Model = "fast"
+		case "--smart":
+			opts.Model = "smart"
+		case "--deep":
+			opts.Model = "deep"
+		case "--no-diff":
+			showDiffPreview = false
+		case "--no-git":
+			gitCommitEnabled = false
+		case "--use-plan":
+			opts.UsePlan = true
+		case "--interactive", "-i":
+			opts.Interactive = true
+		case "--no-project":
+			opts.NoProject = true
+		case "--continue":
+			opts.Continue = true
+		case "--file":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--file needs a path or glob such as \"src/*.go\"")
+			}
+			opts.Files = append(opts.Files, args[i+1])
+			i++
+		case "--timeout":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--timeout needs a number of seconds")
+			}
+			seconds, err := strconv.Atoi(args[i+1])
+			if err != nil || seconds < 1 {
+				return nil, fmt.Errorf("invalid --timeout: %s", args[i+1])
+			}
+			commandTimeout = time.Duration(seconds) * time.Second
+			i++
+		case "--max-iter", "--max-steps":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("%s needs a number of steps", args[i])
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid %s: %s (must be at least 1)", args[i], args[i+1])
+			}
+			maxIterationsFlag = n
+			i++
+		case "--budget":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--budget needs a number of credits")
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid --budget: %s (must be at least 1)", args[i+1])
+			}
+			budgetFlag = n
+			i++
+		case "--session":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("--session needs a session id (see 'keke sessions list')")
+			}
+			opts.SessionID = args[i+1]
+			i++
+		default:
+			promptParts = append(promptParts, args[i])
+		}
+	}
+
+	opts.Prompt = strings.Join(promptParts, " ")
+	if opts.Prompt == "" && !opts.Interactive {
+		return nil, fmt.Errorf("No prompt provided")
+	}
+	return opts, nil
 }
 
 // ─── CONVERSATION LOOP ───────────────────────────────────────────────────────
 // AI can request actions, CLI executes them, sends results back
 
-func conversationLoop(initialPrompt, model string, auth *AuthData) {
-	var conversationHistory []map[string]string
+// AI rounds per run: --max-steps (or --max-iter), else the max_iterations config
+var maxIterationsFlag int
+
+func iterationLimit() int {
+	if maxIterationsFlag > 0 {
+		return maxIterationsFlag
+	}
+	return getConfig().MaxIterations
+}
+
+// moreIterations asks, once a loop has used its steps, whether to allow
+// another limit of them. With --yes, or without a terminal to ask on, the
+// loop just stops: the step cap is what limits unattended runs
+func moreIterations(limit int) bool {
+	if assumeYes || !isTerminal(os.Stdin) {
+		return false
+	}
+	fmt.Fprintln(promptOutput())
+	logWarning(fmt.Sprintf("Step limit reached (%d). The AI is not done yet.", limit))
+	return promptYesNo(fmt.Sprintf("Continue for another %d steps? (y/n)", limit))
+}
+
+// warnMaxIterations tells the user how to pick the session up with more rounds
+func warnMaxIterations(command string, session *SessionData, limit int) {
+	logWarning(fmt.Sprintf("Max iterations reached (%d). AI may need more steps.", limit))
+	logInfo(fmt.Sprintf("Continue with: keke %s --session %s --max-steps %d \"continue\"", command, session.ID, limit*2))
+}
+
+func conversationLoop(session *SessionData, initialPrompt, model string, auth *AuthData) {
+	conversationHistory := session.History
 
 	// Add initial user prompt
 	conversationHistory = append(conversationHistory, map[string]string{
@@ -82,16 +328,26 @@ func conversationLoop(initialPrompt, model string, auth *AuthData) {
 		"content": initialPrompt,
 	})
 
-	maxIterations := 20 // Prevent infinite loops
+	session.Model = model
+	session.LastPrompt = initialPrompt
+	auditSessionID = session.ID
+
+	limit := iterationLimit() // Prevent infinite loops
+	maxIterations := limit
 	iteration := 0
 
+	// Credits spent by this run, checked against the budget before results go back
+	budget := sessionBudget()
+	spent, allowed := 0, budget
+	warnBudgetAboveBalance(budget, auth)
+
 	for iteration < maxIterations {
 		iteration++
 
 		// Send current conversation to AI (via Supabase)
-		response, err := callAI(conversationHistory, model, auth)
+		response, err := callAI(conversationHistory, model, session.Mode, auth)
 		if err != nil {
-			logError(fmt.Sprintf("AI error: %v", err))
+			logRequestError("AI error", err)
 			return
 		}
 
@@ -101,10 +357,26 @@ func conversationLoop(initialPrompt, model string, auth *AuthData) {
 			"content": response.Message,
 		})
 
+		// Persist progress so 'keke status' can see the session
+		session.History = conversationHistory
+		session.CreditsUsed += response.CreditsUsed
+		spent += response.CreditsUsed
+		if err := saveSession(session); err != nil {
+			logWarning(fmt.Sprintf("Failed to save session: %v", err))
+		}
+
 		// Check if AI wants to perform actions
 		if len(response.Actions) == 0 {
 			// AI is done - just display final message
-			fmt.Println(response.Message)
+			if !response.streamed {
+				printMessage(response.Message)
+			}
+			// Code it answered with instead of write_file actions
+			if session.Mode == "ask" && extractAndWriteCodeBlocks(response.Message, session) > 0 {
+				if err := saveSession(session); err != nil {
+					logWarning(fmt.Sprintf("Failed to save session: %v", err))
+				}
+			}
 			printDivider()
 			logInfo(fmt.Sprintf("Total credits used: %d", response.CreditsUsed))
 			return
@@ -112,7 +384,8 @@ func conversationLoop(initialPrompt, model string, auth *AuthData) {
 
 		// AI requested actions - execute them
 		for _, action := range response.Actions {
-			result := executeAction(action)
+			result := truncateToolOutput(executeAction(action))
+			session.recordAction(action, result)
 
 			// Add action result to conversation
 			conversationHistory = append(conversationHistory, map[string]string{
@@ -121,25 +394,64 @@ func conversationLoop(initialPrompt, model string, auth *AuthData) {
 			})
 		}
 
+		// Action results are stored with the session, so stopping here loses nothing
+		session.History = conversationHistory
+		if overBudget(spent, response.CreditsUsed, &allowed, budget) {
+			saveSession(session)
+			return
+		}
+
+		if iteration == maxIterations && moreIterations(limit) {
+			maxIterations += limit
+		}
+
 		// Continue loop - send results back to AI
 	}
 
-	logWarning("Max iterations reached. AI may need more steps.")
+	warnMaxIterations("ask", session, maxIterations)
 }
 
 // ─── CALL AI ─────────────────────────────────────────────────────────────────
 // Sends conversation to Supabase, which calls Anthropic/OpenAI
 
-func callAI(conversation []map[string]string, model string, auth *AuthData) (*AIResponse, error) {
+// mode selects a dedicated server prompt such as "docs"; "ask" is the default
+func callAI(conversation []map[string]string, model, mode string, auth *AuthData) (*AIResponse, error) {
 	payload := map[string]interface{}{
 		"conversation": conversation,
 		"model":        model,
 	}
+	if mode != "" && mode != "ask" {
+		payload["mode"] = mode
+	}
+	return postAI(payload, auth, showStream())
+}
+
+// postAI sends a prepared payload to the AI endpoint and decodes the reply.
+// Only the conversational loops stream: callers that parse the reply or
+// print it themselves pass stream=false
+func postAI(payload map[string]interface{}, auth *AuthData, stream bool) (*AIResponse, error) {
+	if provider := aiProvider(); provider != "" {
+		payload["provider"] = provider
+	}
+	if readOnlyMode {
+		payload["readonly"] = true // the server tells the AI it is in read-only mode
+		payload["disabled_actions"] = writeActions
+	} else if !gitCommitEnabled {
+		payload["disabled_actions"] = []string{"git_commit"}
+	}
+	payload["stream"] = stream
+
+	// Project facts from 'keke context set'
+	if facts, err := readProjectContext(); err != nil {
+		logWarning(fmt.Sprintf("Ignoring project context: %v", err))
+	} else if len(facts) > 0 {
+		payload["context"] = facts
+	}
 
 	jsonData, _ := json.Marshal(payload)
-	resp, err := makeAuthenticatedRequest(
+	resp, err := makeAuthenticatedRequestWithRetry(
 		"POST",
-		EndpointAI,
+		apiEndpoint(EndpointAI),
 		bytes.NewBuffer(jsonData),
 		auth,
 	)
@@ -149,26 +461,59 @@ func callAI(conversation []map[string]string, model string, auth *AuthData) (*AI
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 402 {
-		return nil, fmt.Errorf("insufficient credits")
+		return nil, errInsufficientCredits
 	}
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server error: %s", string(body))
+		return nil, fmt.Errorf("server error: %s", redactBody(body))
 	}
 
-	var response AIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
-	}
+	return decodeAIResponse(resp, stream)
+}
 
-	return &response, nil
+// decodeJSONReply parses the JSON object in an AI message into v, tolerating
+// prose or code fences around it
+func decodeJSONReply(message string, v interface{}) error {
+	start, end := strings.Index(message, "{"), strings.LastIndex(message, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in reply")
+	}
+	return json.Unmarshal([]byte(message[start:end+1]), v)
 }
 
 // ─── EXECUTE ACTION ──────────────────────────────────────────────────────────
 // CLI executes actions requested by AI (with permission checks)
 
+// Largest action result sent back to the AI (KEKE_MAX_TOOL_OUTPUT overrides)
+const maxToolOutputBytes = 32 * 1024
+
+// truncateToolOutput keeps the head and tail of an oversized action result so
+// a single big file or noisy command does not blow up the conversation
+func truncateToolOutput(result string) string {
+	limit := maxToolOutputBytes
+	if value := os.Getenv("KEKE_MAX_TOOL_OUTPUT"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			limit = n
+		}
+	}
+
+	if len(result) <= limit {
+		return result
+	}
+
+	marker := fmt.Sprintf("\n...[truncated, %d bytes total]...\n", len(result))
+	head := limit / 2
+	tail := limit - head
+	return result[:head] + marker + result[len(result)-tail:]
+}
+
 func executeAction(action Action) string {
+	if readOnlyMode && containsString(writeActions, action.Type) {
+		logWarning(fmt.Sprintf("Refused %s in read-only mode", action.Type))
+		return fmt.Sprintf("Refused: %s is not available in read-only mode (--no-project); answer from what you can read", action.Type)
+	}
+
 	switch action.Type {
 	case "read_file":
 		return handleReadFile(action)
@@ -178,6 +523,8 @@ func executeAction(action Action) string {
 		return handleExecuteCommand(action)
 	case "list_files":
 		return handleListFiles(action)
+	case "git_commit":
+		return handleGitCommit(action)
 	default:
 		return fmt.Sprintf("Unknown action type: %s", action.Type)
 	}
@@ -185,14 +532,13 @@ func executeAction(action Action) string {
 
 // ─── READ FILE ───────────────────────────────────────────────────────────────
 
-func handleReadFile(action Action) string {
+func handleReadFile(action Action) (result string) {
+	defer func() { recordAudit(action, result) }()
+
 	path := action.Path
 
-	// Check permission
-	if !checkPermission("read") {
-		if !requestPermission("read", fmt.Sprintf("AI wants to read: %s", path)) {
-			return "Permission denied by user"
-		}
+	if refusal := guardRead(path, fmt.Sprintf("AI wants to read: %s", path)); refusal != "" {
+		return refusal
 	}
 
 	content, err := os.ReadFile(path)
@@ -204,17 +550,67 @@ func handleReadFile(action Action) string {
 	return string(content)
 }
 
+// guardRead runs the checks every file read for the AI goes through: the
+// project boundary, the read permission, .kekeignore, then the secrets
+// confirmation. It returns "" when path may be read, or the refusal to send
+// back to the AI
+func guardRead(path, message string) string {
+	// Refuse before asking: no grant can allow a read outside the project
+	if _, err := resolveInProject(path); err != nil {
+		logWarning(fmt.Sprintf("Refused to read %s: %v", path, err))
+		return fmt.Sprintf("Refused: %s is outside the project", path)
+	}
+
+	if !ensurePermission("read", path, message) {
+		return "Permission denied by user"
+	}
+
+	if isIgnored(path, false, loadIgnorePatterns()) {
+		logWarning(fmt.Sprintf("Refused to read ignored path: %s", path))
+		return fmt.Sprintf("Refused: %s is excluded by %s", path, kekeignoreFile)
+	}
+
+	// Secrets need a second confirmation, whatever was granted
+	if isSensitiveFile(path) && !confirmSensitiveRead(path) {
+		return fmt.Sprintf("Refused: %s looks like a secrets file and the user did not confirm reading it", path)
+	}
+	return ""
+}
+
 // ─── WRITE FILE ──────────────────────────────────────────────────────────────
 
-func handleWriteFile(action Action) string {
+func handleWriteFile(action Action) (result string) {
+	defer func() { recordAudit(action, result) }()
+
 	path := action.Path
 	content := action.Content
 
 	// Check permission
-	if !checkPermission("write") {
-		if !requestPermission("write", fmt.Sprintf("AI wants to write: %s", path)) {
-			return "Permission denied by user"
-		}
+	// Refuse before asking: no grant can allow a write outside the project
+	if _, err := resolveInProject(path); err != nil {
+		logWarning(fmt.Sprintf("Refused to write %s: %v", path, err))
+		return fmt.Sprintf("Refused: %s is outside the project", path)
+	}
+
+	// NUL bytes mean the model sent mangled or binary content, never source
+	if strings.ContainsRune(content, 0) {
+		logWarning(fmt.Sprintf("Refused to write %s: content contains NUL bytes (binary or corrupted output)", path))
+		return fmt.Sprintf("Refused: content for %s contains NUL bytes; write_file only takes text", path)
+	}
+
+	if !ensurePermission("write", path, fmt.Sprintf("AI wants to write: %s", path)) {
+		return "Permission denied by user"
+	}
+
+	if dryRun {
+		recordDryRunWrite(path, content)
+		return fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), path)
+	}
+
+	// Show the change and let the user veto it
+	if showDiffPreview && !previewWrite(path, content) {
+		logInfo(fmt.Sprintf("Skipped: %s", path))
+		return fmt.Sprintf("User rejected the change to %s", path)
 	}
 
 	// Create snapshot BEFORE writing (CLI-side, no AI involved)
@@ -223,61 +619,290 @@ func handleWriteFile(action Action) string {
 	}
 
 	// Write file
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	description := fmt.Sprintf("Updated (%s)", formatBytes(int64(len(content))))
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		description = fmt.Sprintf("Created (%s)", formatBytes(int64(len(content))))
+	}
+
+	mode := writeMode(path, content, action.Executable)
+	if err := writeFileToWorkspace(path, []byte(content), mode); err != nil {
 		return fmt.Sprintf("Error writing file: %v", err)
 	}
+	appendChangelog(ChangelogEntry{Action: "write_file", Target: path, Description: description})
 
-	logSuccess(fmt.Sprintf("Wrote: %s", path))
+	if mode&0111 != 0 {
+		logSuccess(fmt.Sprintf("Wrote: %s (executable)", path))
+	} else {
+		logSuccess(fmt.Sprintf("Wrote: %s", path))
+	}
 	return fmt.Sprintf("Successfully wrote %d bytes to %s", len(content), path)
 }
 
+// ─── DRY RUN WRITES ──────────────────────────────────────────────────────────
+// With --dry-run the conversation runs as usual and the AI is told its writes
+// succeeded, but each one is only previewed. finish() prints the totals
+
+const dryRunPreviewLines = 20
+
+// dryRunWrites maps each path the AI would write to whether it exists
+var (
+	dryRunWrites     = map[string]bool{}
+	dryRunWriteOrder []string
+)
+
+// recordDryRunWrite shows the start of a write that --dry-run suppressed
+func recordDryRunWrite(path, content string) {
+	if _, seen := dryRunWrites[path]; !seen {
+		_, err := os.Stat(path)
+		dryRunWrites[path] = err == nil
+		dryRunWriteOrder = append(dryRunWriteOrder, path)
+	}
+
+	logInfo(fmt.Sprintf("[DRY RUN] Would write %s (%d bytes)", path, len(content)))
+	if jsonMode || logLevel == levelQuiet {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for i, line := range lines {
+		if i == dryRunPreviewLines {
+			fmt.Printf("%s  ... %d more lines%s\n", dim, len(lines)-dryRunPreviewLines, reset)
+			break
+		}
+		fmt.Printf("%s  %s%s\n", dim, line, reset)
+	}
+}
+
+// printDryRunSummary totals the writes a dry run suppressed
+func printDryRunSummary() {
+	if !dryRun || len(dryRunWriteOrder) == 0 {
+		return
+	}
+	created, modified := 0, 0
+	for _, path := range dryRunWriteOrder {
+		if dryRunWrites[path] {
+			modified++
+		} else {
+			created++
+		}
+	}
+	logInfo(fmt.Sprintf("[DRY RUN] Would create: %d files, modify: %d files", created, modified))
+}
+
+// writeFileToWorkspace is the only way AI-requested content reaches disk. It
+// refuses paths that resolve outside the project root
+func writeFileToWorkspace(path string, content []byte, mode os.FileMode) error {
+	resolved, err := resolveInProject(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(resolved, content, mode); err != nil {
+		return err
+	}
+	// WriteFile only applies mode to new files
+	return os.Chmod(resolved, mode)
+}
+
+// writeMode picks the permissions for a write: an existing file keeps its
+// own, and scripts (a #! line, a .sh name or the executable field) get the
+// executable bit
+func writeMode(path, content string, executable bool) os.FileMode {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	if executable || strings.HasPrefix(content, "#!") || ext == ".sh" || ext == ".bash" {
+		mode |= (mode & 0444) >> 2 // execute wherever read is allowed
+	}
+	return mode
+}
+
+// resolveInProject cleans path and resolves symlinks in the part of it that
+// exists, failing when the result is outside the current project directory
+func resolveInProject(path string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return "", err
+	}
+
+	abs, err := filepath.Abs(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve the longest existing prefix; the rest does not exist yet
+	existing, rest := abs, ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(existing); err == nil {
+			abs = filepath.Join(resolved, rest)
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("outside project %s", root)
+	}
+	return abs, nil
+}
+
 // ─── EXECUTE COMMAND ─────────────────────────────────────────────────────────
 
-func handleExecuteCommand(action Action) string {
+// How long an AI-requested command may run, set by --timeout. Zero uses
+// command_timeout_seconds from the config (or KEKE_CMD_TIMEOUT)
+var commandTimeout time.Duration
+
+func effectiveCommandTimeout() time.Duration {
+	if commandTimeout > 0 {
+		return commandTimeout
+	}
+	return time.Duration(getConfig().CommandTimeout) * time.Second
+}
+
+func handleExecuteCommand(action Action) (result string) {
+	defer func() { recordAudit(action, result) }()
+
 	command := action.Command
 
 	// Check permission
-	if !checkPermission("execute") {
-		if !requestPermission("execute", fmt.Sprintf("AI wants to run: %s", command)) {
-			return "Permission denied by user"
+	if !ensurePermission("execute", command, fmt.Sprintf("AI wants to run: %s", command)) {
+		return "Permission denied by user"
+	}
+
+	// High-risk commands are confirmed every time, whatever was granted
+	if reason := dangerousCommand(command); reason != "" && !dryRun {
+		if !confirmDangerousCommand(command, reason) {
+			return fmt.Sprintf("Refused: dangerous command (%s) was not confirmed by the user", reason)
 		}
 	}
 
-	logInfo(fmt.Sprintf("Running: %s", command))
+	if dryRun {
+		logInfo(fmt.Sprintf("[DRY RUN] Would run: %s", command))
+		return "Command completed (dry run, no output)"
+	}
+
+	timeout := effectiveCommandTimeout()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd, container, err := shellCommand(ctx, command)
+	if err != nil {
+		logError(err.Error())
+		return fmt.Sprintf("Refused: %v", err)
+	}
+	if container != "" {
+		logInfo(fmt.Sprintf("Running in sandbox (%s): %s", getConfig().SandboxImage, command))
+	} else {
+		logInfo(fmt.Sprintf("Running: %s", command))
+	}
+
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		stopContainer(container)
+		return killProcessGroup(cmd)
+	}
+
+	// Ctrl+C stops the command rather than keke; the AI is told and the
+	// conversation goes on
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	interrupted := make(chan struct{})
+	go func() {
+		select {
+		case <-interrupts:
+			close(interrupted)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
-	cmd := exec.Command("sh", "-c", command)
 	output, err := cmd.CombinedOutput()
 
+	select {
+	case <-interrupted:
+		fmt.Println()
+		logWarning("Command interrupted by user")
+		printPartialOutput(output)
+		appendChangelog(ChangelogEntry{Action: "execute_command", Target: command, Description: "Interrupted by user"})
+		return fmt.Sprintf("Command interrupted by user\nOutput: %s", string(output))
+	default:
+	}
+
+	if ctx.Err() == context.DeadlineExceeded {
+		logWarning(fmt.Sprintf("Command timed out after %s", timeout))
+		printPartialOutput(output)
+		appendChangelog(ChangelogEntry{Action: "execute_command", Target: command, Description: fmt.Sprintf("Timed out after %s", timeout)})
+		return fmt.Sprintf("Command timed out after %s (it may be a server or watcher that never exits; run it in the background or with a limit)\nOutput: %s", timeout, string(output))
+	}
+
 	if err != nil {
+		appendChangelog(ChangelogEntry{Action: "execute_command", Target: command, Description: fmt.Sprintf("Failed (%v)", err)})
 		return fmt.Sprintf("Command failed: %v\nOutput: %s", err, string(output))
 	}
 
+	appendChangelog(ChangelogEntry{Action: "execute_command", Target: command, Description: "Completed"})
 	logSuccess("Command completed")
 	return string(output)
 }
 
+// printPartialOutput shows what a stopped command printed before it was
+// killed, up to its last 20 lines
+func printPartialOutput(output []byte) {
+	text := strings.TrimRight(string(output), "\n")
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > 20 {
+		logInfo(fmt.Sprintf("Partial output (last 20 of %d lines):", len(lines)))
+		lines = lines[len(lines)-20:]
+	} else {
+		logInfo("Partial output:")
+	}
+	if jsonMode {
+		printMessage(strings.Join(lines, "\n"))
+		return
+	}
+	for _, line := range lines {
+		fmt.Printf("%s  %s%s\n", dim, line, reset)
+	}
+}
+
 // ─── LIST FILES ──────────────────────────────────────────────────────────────
 
-func handleListFiles(action Action) string {
+func handleListFiles(action Action) (result string) {
+	defer func() { recordAudit(action, result) }()
+
 	dir := action.Path
 	if dir == "" {
 		dir = "."
 	}
 
 	// Check permission
-	if !checkPermission("read") {
-		if !requestPermission("read", "AI wants to list files in workspace") {
-			return "Permission denied by user"
-		}
+	if !ensurePermission("read", dir, fmt.Sprintf("AI wants to list files in: %s", dir)) {
+		return "Permission denied by user"
 	}
 
+	ignore := loadIgnorePatterns()
+
 	var files []string
 	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
-		// Skip .keke, .git, node_modules
-		if strings.Contains(path, ".keke") || strings.Contains(path, ".git") || strings.Contains(path, "node_modules") {
+		// Skip .keke, .git and anything in .kekeignore
+		if isIgnored(path, info.IsDir(), ignore) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
@@ -297,53 +922,233 @@ func handleListFiles(action Action) string {
 	return strings.Join(files, "\n")
 }
 
+// ─── GIT COMMIT ──────────────────────────────────────────────────────────────
+
+// handleGitCommit commits the staged changes with the AI's message. The
+// message and staged files are shown and the user is asked every time
+func handleGitCommit(action Action) (result string) {
+	defer func() { recordAudit(action, result) }()
+
+	if !gitCommitEnabled {
+		return "Refused: git_commit is disabled (--no-git)"
+	}
+	message := strings.TrimSpace(action.Message)
+	if message == "" {
+		return "Error: git_commit needs a message"
+	}
+
+	out, err := exec.Command("git", "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		return fmt.Sprintf("Error: cannot list staged files (is this a git repository?): %v", err)
+	}
+	var staged []string
+	for _, file := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if file != "" {
+			staged = append(staged, file)
+		}
+	}
+	if len(staged) == 0 {
+		return "Error: nothing is staged; stage files with 'git add' first"
+	}
+
+	details := fmt.Sprintf("AI wants to commit %d staged files:\n", len(staged))
+	for _, file := range staged {
+		details += fmt.Sprintf("  %s\n", file)
+	}
+	details += fmt.Sprintf("\nMessage:\n%s", message)
+
+	// Always asked: a commit is never covered by a saved grant
+	if !requestPermission("execute", "git commit", details) {
+		lastPermission = "denied"
+		return "Permission denied by user"
+	}
+	lastPermission = "approved"
+
+	if dryRun {
+		logInfo(fmt.Sprintf("[DRY RUN] Would commit %d files: %s", len(staged), firstLine(message)))
+		return fmt.Sprintf("Committed %d files (dry run)", len(staged))
+	}
+
+	output, err := exec.Command("git", "commit", "-m", message).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("Command failed: git commit: %v\n%s", err, output)
+	}
+
+	appendChangelog(ChangelogEntry{Action: "git_commit", Target: message, Description: fmt.Sprintf("Committed %d files", len(staged))})
+	logSuccess(fmt.Sprintf("Committed %d files: %s", len(staged), firstLine(message)))
+	return string(output)
+}
+
 // ─── PERMISSION CHECKING ─────────────────────────────────────────────────────
 
-func checkPermission(permType string) bool {
+// ensurePermission checks for a saved grant and otherwise asks the user with
+// message. Every action handler goes through it; the outcome is kept in
+// lastPermission for the audit log
+func ensurePermission(permType, target, message string) bool {
+	switch {
+	case checkPermission(permType, target):
+		lastPermission = "pre-granted"
+	case requestPermission(permType, target, message):
+		lastPermission = "approved"
+	default:
+		lastPermission = "denied"
+		return false
+	}
+	return true
+}
+
+// checkPermission reports whether target (a path, or a command for
+// "execute") matches a saved grant of permType
+func checkPermission(permType, target string) bool {
+	if permType == "read" && getConfig().AutoApproveRead {
+		if _, err := resolveInProject(target); err == nil {
+			return true
+		}
+	}
+
 	perms, err := readPermissions()
 	if err != nil {
 		return false
 	}
 
-	switch permType {
-	case "read":
-		return perms.Read
-	case "write":
-		return perms.Write
-	case "execute":
-		return perms.Execute
-	default:
-		return false
+	for _, pattern := range perms.grants(permType) {
+		if matchGrant(permType, pattern, target) {
+			return true
+		}
 	}
+	return false
 }
 
-func requestPermission(permType, message string) bool {
+// requestPermission asks the user to allow permType on target. The grant can
+// cover just that target, its directory (its command prefix for execute), or
+// everything
+func requestPermission(permType, target, message string) bool {
 	fmt.Println()
 	logWarning("PERMISSION REQUEST")
 	fmt.Println(message)
 	fmt.Println()
 
-	response := prompt("Allow? (y/n)")
-	allowed := strings.ToLower(response) == "y" || strings.ToLower(response) == "yes"
-
-	if allowed {
-		// Save permission
-		perms, _ := readPermissions()
-		switch permType {
-		case "read":
-			perms.Read = true
-		case "write":
-			perms.Write = true
-		case "execute":
-			perms.Execute = true
-		}
-		writePermissions(perms)
-		logSuccess("Permission granted and saved")
+	// --yes allows it for this run only; unattended runs never save grants
+	if assumeYes {
+		logInfo(fmt.Sprintf("Allowed by --yes (%s %s, not saved)", permType, target))
+		return true
+	}
+
+	scope := permissionScope(permType, target)
+	var answer string
+	if permType == "execute" {
+		answer = prompt(fmt.Sprintf("Allow? [y] this command  [p] commands starting with %q  [a] always  [n] no", strings.TrimSuffix(scope, "*")))
 	} else {
-		logError("Permission denied")
+		answer = prompt(fmt.Sprintf("Allow? [y] this file  [d] directory %s  [a] always  [n] no", scope))
+	}
+
+	pattern := ""
+	switch strings.ToLower(answer) {
+	case "y", "yes", "f":
+		pattern = target
+	case "d", "p":
+		pattern = scope
+	case "a", "always":
+		pattern = "*"
+	}
+
+	if pattern == "" {
+		logWarning("Permission denied")
+		return false
+	}
+
+	if readOnlyMode {
+		logSuccess(fmt.Sprintf("Permission granted for this run (%s %s, not saved outside a project)", permType, pattern))
+		return true
+	}
+
+	// Save permission
+	perms, _ := readPermissions()
+	perms.grant(permType, pattern)
+	writePermissions(perms)
+	logSuccess(fmt.Sprintf("Permission granted and saved (%s %s)", permType, pattern))
+	return true
+}
+
+// permissionScope returns the wider grant offered next to target: its
+// directory for paths, or its first word for commands
+func permissionScope(permType, target string) string {
+	if permType == "execute" {
+		if fields := strings.Fields(target); len(fields) > 0 {
+			return fields[0] + " *"
+		}
+		return target
+	}
+
+	dir := target
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		dir = filepath.Dir(target)
+	}
+	return filepath.ToSlash(filepath.Clean(dir)) + "/"
+}
+
+// matchGrant reports whether a saved grant pattern covers target. Paths use
+// filepath.Match globs ("src/*.go"), a trailing "/" grants a whole
+// directory, and "*" grants everything, all within the project: a path
+// outside it never matches. Command patterns ending in "*" match by prefix
+// ("go test*"), but not a command that chains or redirects to another
+func matchGrant(permType, pattern, target string) bool {
+	if permType == "execute" {
+		if pattern == "*" || pattern == target {
+			return true
+		}
+		return strings.HasSuffix(pattern, "*") && strings.HasPrefix(target, strings.TrimSuffix(pattern, "*")) &&
+			!hasShellOperator(target)
+	}
+
+	// A symlink inside the project may still lead out of it
+	if _, err := resolveInProject(target); err != nil {
+		return false
+	}
+	target, ok := projectRelativePath(target)
+	if !ok {
+		return false
+	}
+	if pattern == "*" {
+		return true
+	}
+
+	dirGrant := strings.HasSuffix(pattern, "/")
+	if pattern, ok = projectRelativePath(pattern); !ok {
+		return false
+	}
+	if dirGrant {
+		return pattern == "." || target == pattern || strings.HasPrefix(target, pattern+string(filepath.Separator))
 	}
 
-	return allowed
+	matched, _ := filepath.Match(pattern, target)
+	return matched
+}
+
+// Shell syntax that runs a second command or writes a file
+var shellOperators = []string{";", "&", "|", "`", "$(", ">", "\n"}
+
+func hasShellOperator(command string) bool {
+	for _, op := range shellOperators {
+		if strings.Contains(command, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// projectRelativePath returns path relative to the project root, cleaned, or
+// false when it lies outside the project
+func projectRelativePath(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(filepath.Dir(projectDir()), abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
 }
 
 // ─── SNAPSHOT (CLI-SIDE, NO AI) ──────────────────────────────────────────────
@@ -355,17 +1160,27 @@ func createSnapshot(filePath string) error {
 		return err // File doesn't exist yet, no snapshot needed
 	}
 
-	// Create snapshot filename
-	timestamp := time.Now().Format("20060102_150405")
-	snapshotName := fmt.Sprintf("%s.%s.snap", filepath.Base(filePath), timestamp)
-	snapshotPath := filepath.Join(projectSnapshotsDir(), snapshotName)
+	// Snapshots mirror the project layout: src/a.go -> snapshots/src/a.go.<time>.snap.gz
+	key := snapshotKey(filePath)
+	snapshotPath, err := newSnapshotPath(key, time.Now())
+	if err != nil {
+		return err
+	}
 
 	// Write snapshot
-	if err := os.WriteFile(snapshotPath, content, 0644); err != nil {
+	if err := writeSnapshotFile(snapshotPath, content); err != nil {
 		return err
 	}
 
-	logInfo(fmt.Sprintf("Snapshot: %s", snapshotName))
+	snapshotName, _ := filepath.Rel(projectSnapshotsDir(), snapshotPath)
+	logInfo(fmt.Sprintf("Snapshot: %s", filepath.ToSlash(snapshotName)))
+
+	// Keep at most max_snapshots_per_file automatic snapshots of this file
+	if limit := getConfig().MaxSnapshots; limit > 0 {
+		if snapshots, err := loadSnapshots(); err == nil {
+			deleteSnapshots(selectSnapshotsToPrune(snapshots[key], limit, 0))
+		}
+	}
 	return nil
 }
 
@@ -375,7 +1190,17 @@ func readPermissions() (*Permissions, error) {
 		return &Permissions{}, nil // Return empty permissions if file doesn't exist
 	}
 	var perms Permissions
-	json.Unmarshal(data, &perms)
+	if err := json.Unmarshal(data, &perms); err != nil {
+		// Older projects stored one boolean per type; true meant everything
+		var legacy struct{ Read, Write, Execute bool }
+		if json.Unmarshal(data, &legacy) == nil {
+			for permType, granted := range map[string]bool{"read": legacy.Read, "write": legacy.Write, "execute": legacy.Execute} {
+				if granted {
+					perms.grant(permType, "*")
+				}
+			}
+		}
+	}
 	return &perms, nil
 }
 
@@ -386,15 +1211,19 @@ type AIResponse struct {
 	Actions     []Action `json:"actions"`
 	CreditsUsed int      `json:"credits_used"`
 	Done        bool     `json:"done"`
+
+	streamed bool // Message was already printed while streaming
 }
 
 // Add to existing Action type in ask.go
 
 type Action struct {
-	Type    string `json:"type"`    // read_file, write_file, execute_command, etc.
-	Path    string `json:"path"`    // for file operations
-	Content string `json:"content"` // for write_file
-	Command string `json:"command"` // for execute_command
+	Type       string `json:"type"`                 // read_file, write_file, execute_command, etc.
+	Path       string `json:"path"`                 // for file operations
+	Content    string `json:"content"`              // for write_file
+	Command    string `json:"command"`              // for execute_command
+	Executable bool   `json:"executable,omitempty"` // for write_file: mark the file executable
+	Message    string `json:"message,omitempty"`    // for git_commit
 	
 	// ✅ NEW: Research-specific fields
 	Format       string                 `json:"format"`        // for load_dataset
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ─── AUDIT LOG ───────────────────────────────────────────────────────────────
+// Every read, write, command and listing the AI asks for is appended to
+// .keke/audit.log as one JSON line. 'keke audit' shows it newest first
+
+// AuditEntry - one line of .keke/audit.log
+type AuditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Action     string    `json:"action"`               // read_file, write_file, execute_command, list_files, git_commit
+	Target     string    `json:"target"`               // path, command for execute_command, message for git_commit
+	Result     string    `json:"result"`               // success or failure
+	Permission string    `json:"permission,omitempty"` // pre-granted, approved or denied; empty when refused before asking
+	Session    string    `json:"session,omitempty"`
+	DryRun     bool      `json:"dry_run,omitempty"`
+}
+
+// Set by ensurePermission and the conversation loops so entries can say how
+// an action was allowed and which session asked for it
+var (
+	lastPermission  string
+	auditSessionID  string
+	auditActionList = []string{"read_file", "write_file", "execute_command", "list_files", "git_commit"}
+)
+
+// recordAudit appends action and the outcome of result to the audit log.
+// Failures to log are reported but never stop the action
+func recordAudit(action Action, result string) {
+	permission := lastPermission
+	lastPermission = ""
+	if !isProjectInitialized() {
+		return
+	}
+
+	target := action.Path
+	switch action.Type {
+	case "execute_command":
+		target = action.Command
+	case "git_commit":
+		target = firstLine(action.Message)
+	}
+	outcome := "success"
+	if actionFailed(result) {
+		outcome = "failure"
+	}
+
+	data, err := json.Marshal(AuditEntry{
+		Timestamp:  time.Now().UTC(),
+		Action:     action.Type,
+		Target:     target,
+		Result:     outcome,
+		Permission: permission,
+		Session:    auditSessionID,
+		DryRun:     dryRun,
+	})
+	if err != nil {
+		return
+	}
+
+	f, err := os.OpenFile(projectAuditLogFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		logWarning(fmt.Sprintf("Failed to write audit log: %v", err))
+		return
+	}
+	defer f.Close()
+	f.Write(append(data, '\n'))
+}
+
+// actionFailed reports whether an action handler's result describes a
+// refusal or an error rather than output
+func actionFailed(result string) bool {
+	for _, prefix := range []string{"Permission denied", "Refused", "Error", "Command failed", "Command timed out", "Command interrupted", "User rejected"} {
+		if strings.HasPrefix(result, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func readAuditLog() ([]AuditEntry, error) {
+	f, err := os.Open(projectAuditLogFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // skip damaged lines
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// ─── AUDIT COMMAND ───────────────────────────────────────────────────────────
+
+func handleAudit(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	var since time.Time
+	actionType := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--since":
+			if i+1 >= len(args) {
+				logError("--since needs a time (2026-01-31, 2026-01-31T15:04:05Z or an age like 7d)")
+				return
+			}
+			t, err := parseSince(args[i+1])
+			if err != nil {
+				logError(err.Error())
+				return
+			}
+			since = t
+			i++
+		case "--action":
+			if i+1 >= len(args) {
+				logError(fmt.Sprintf("--action needs one of: %s", strings.Join(auditActionList, ", ")))
+				return
+			}
+			actionType = args[i+1]
+			if !containsString(auditActionList, actionType) {
+				logError(fmt.Sprintf("Unknown action '%s'. Use one of: %s", actionType, strings.Join(auditActionList, ", ")))
+				return
+			}
+			i++
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke audit [--since <time>] [--action <type>]")
+			return
+		}
+	}
+
+	entries, err := readAuditLog()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read audit log: %v", err))
+		return
+	}
+
+	// Newest first
+	var matches []AuditEntry
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Timestamp.Before(since) || (actionType != "" && e.Action != actionType) {
+			continue
+		}
+		matches = append(matches, e)
+	}
+
+	if jsonMode {
+		emitJSON(matches)
+		return
+	}
+
+	if len(matches) == 0 {
+		logInfo("No matching audit entries")
+		return
+	}
+
+	printDivider()
+	fmt.Printf("%s%-19s %-15s %-7s %-11s %-17s %s%s\n",
+		bold, "TIME", "ACTION", "RESULT", "PERMISSION", "SESSION", "TARGET", reset)
+	for _, e := range matches {
+		result := green + "ok     " + reset
+		if e.Result == "failure" {
+			result = red + "failed " + reset
+		}
+		permission := e.Permission
+		if permission == "" {
+			permission = "-"
+		}
+		session := e.Session
+		if session == "" {
+			session = "-"
+		}
+		target := truncateText(e.Target, 60)
+		if e.DryRun {
+			target = dim + "[dry run] " + reset + target
+		}
+		fmt.Printf("%-19s %-15s %s %-11s %-17s %s\n",
+			e.Timestamp.Local().Format("2006-01-02 15:04:05"), e.Action, result, permission, session, target)
+	}
+	printDivider()
+	logInfo(fmt.Sprintf("Showing %d of %d entries (%s)", len(matches), len(entries), projectAuditLogFile()))
+}
+
+// parseSince accepts an RFC 3339 time, a date, or an age such as 7d or 12h
+func parseSince(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if age, err := parseAge(value); err == nil {
+		return time.Now().Add(-age), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %s (use 2026-01-31, 2026-01-31T15:04:05Z or an age like 7d)", value)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -2,23 +2,48 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	mathrand "math/rand"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 )
 
 // ─── LOGIN WITH CHOICE ───────────────────────────────────────────────────────
 
-func handleLogin() {
+func handleLogin(args []string) {
+	// Parse flags
+	port := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--port" && i+1 < len(args) {
+			port = args[i+1]
+			i++
+		}
+	}
+
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			logError(fmt.Sprintf("Invalid port: %s", port))
+			return
+		}
+	}
+
 	if isLoggedIn() {
 		auth, _ := readAuth()
 		logWarning(fmt.Sprintf("Already logged in as %s", auth.Email))
@@ -38,7 +63,7 @@ func handleLogin() {
 	case "1":
 		handleEmailPasswordLogin()
 	case "2":
-		handleGmailLogin()
+		handleGmailLogin(port)
 	default:
 		logError("Invalid choice. Please enter 1 or 2")
 	}
@@ -77,8 +102,8 @@ func handleEmailPasswordLogin() {
 	}
 
 	jsonData, _ := json.Marshal(payload)
-	resp, err := http.Post(
-		EndpointAuth+"/login",
+	resp, err := httpClient().Post(
+		apiEndpoint(EndpointAuth+"/login"),
 		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
@@ -95,7 +120,7 @@ func handleEmailPasswordLogin() {
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		logError(fmt.Sprintf("Login failed: %s", string(body)))
+		logError(fmt.Sprintf("Login failed: %s", redactBody(body)))
 		return
 	}
 
@@ -121,7 +146,9 @@ func handleEmailPasswordLogin() {
 
 // ─── GMAIL OAUTH LOGIN ───────────────────────────────────────────────────────
 
-func handleGmailLogin() {
+// handleGmailLogin runs the OAuth flow. If port is empty the first free port
+// in CallbackPort..CallbackPort+10 is used, falling back to an OS-assigned one
+func handleGmailLogin(port string) {
 	logInfo("Opening browser for Gmail authentication...")
 
 	// Generate PC hash
@@ -131,6 +158,58 @@ func handleGmailLogin() {
 		return
 	}
 
+	// Build OAuth URL - points to your Supabase function
+	authCode, err := waitForAuthCode(port, "Logged in", 60*time.Second, func(callbackURL string) string {
+		return fmt.Sprintf("%s?redirect=%s&provider=google", apiEndpoint(EndpointAuth), callbackURL)
+	})
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	// Exchange code for token (calls Supabase function)
+	logInfo("Exchanging auth code for token...")
+	authResp, err := exchangeAuthCode(authCode, pcHash, "oauth")
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	authData := authResp.AuthData
+	authData.PCHash = pcHash
+	if err := writeAuth(&authData); err != nil {
+		logError(fmt.Sprintf("Failed to save auth: %v", err))
+		return
+	}
+
+	logSuccess("Logged in successfully")
+	printDivider()
+	logInfo(fmt.Sprintf("Account: %s", authData.Email))
+	logInfo(fmt.Sprintf("Plan:    %s", authData.Plan))
+	logInfo(fmt.Sprintf("PC ID:   %s", pcHash[:8]+"..."))
+	printDivider()
+}
+
+// authResponse - what the auth endpoints return: the session and, for a new
+// account, its starting credit balance
+type authResponse struct {
+	AuthData
+	Credits int `json:"credits,omitempty"`
+}
+
+// waitForAuthCode opens the browser at the URL buildURL makes from the local
+// callback address, then waits for the redirect carrying the auth code
+func waitForAuthCode(port, title string, timeout time.Duration, buildURL func(callbackURL string) string) (string, error) {
+	// Bind callback listener first so the page and redirect use the real port
+	listener, err := listenForCallback(port)
+	if err != nil {
+		if port != "" {
+			return "", fmt.Errorf("Port %s is busy. Close whatever is using it or pick another with --port", port)
+		}
+		return "", fmt.Errorf("Could not open a local port for the login callback: %v", err)
+	}
+	boundPort := listener.Addr().(*net.TCPAddr).Port
+
 	// Start local callback server
 	authCodeChan := make(chan string, 1)
 	errorChan := make(chan error, 1)
@@ -149,7 +228,7 @@ func handleGmailLogin() {
 		fmt.Fprintf(w, `<!DOCTYPE html>
 <html>
 <head>
-	<title>Keke - Logged In</title>
+	<title>Keke - %s</title>
 	<style>
 		body {
 			font-family: monospace;
@@ -180,33 +259,23 @@ func handleGmailLogin() {
 </head>
 <body>
 	<div class="box">
-		<div class="title">✓ Logged in</div>
+		<div class="title">✓ %s</div>
 		<div class="msg">You can close this window and return to your terminal</div>
+		<div class="msg">Callback received on localhost:%d</div>
 	</div>
 </body>
-</html>`)
+</html>`, title, title, boundPort)
 
 		authCodeChan <- code
 	})
 
 	server := &http.Server{
-		Addr:    ":" + CallbackPort,
 		Handler: mux,
 	}
 
-	// Check if port available
-	listener, err := net.Listen("tcp", server.Addr)
-	if err != nil {
-		logError(fmt.Sprintf("Port %s is busy. Close whatever is using it and try again", CallbackPort))
-		return
-	}
-
-	// Build OAuth URL - points to your Supabase function
-	callbackURL := fmt.Sprintf("http://localhost:%s%s", CallbackPort, CallbackPath)
-	authURL := fmt.Sprintf("%s?redirect=%s&provider=google", EndpointAuth, callbackURL)
-
 	// Open browser
-	openBrowser(authURL)
+	callbackURL := fmt.Sprintf("http://localhost:%d%s", boundPort, CallbackPath)
+	openBrowser(buildURL(callbackURL))
 
 	// Start server
 	go func() {
@@ -218,70 +287,97 @@ func handleGmailLogin() {
 	logInfo("Waiting for authentication...")
 
 	// Wait for callback or timeout
-	var authCode string
+	defer server.Close()
 	select {
-	case authCode = <-authCodeChan:
-		server.Close()
+	case authCode := <-authCodeChan:
+		return authCode, nil
 	case err := <-errorChan:
-		server.Close()
-		logError(err.Error())
-		return
-	case <-time.After(60 * time.Second):
-		server.Close()
-		logError("Authentication timed out after 60 seconds")
-		return
+		return "", err
+	case <-time.After(timeout):
+		return "", fmt.Errorf("Authentication timed out after %s", timeout)
 	}
+}
 
-	// Exchange code for token (calls Supabase function)
-	logInfo("Exchanging auth code for token...")
-
+// exchangeAuthCode trades a callback code for a session bound to pcHash
+func exchangeAuthCode(code, pcHash, method string) (*authResponse, error) {
 	payload := map[string]string{
-		"code":    authCode,
+		"code":    code,
 		"pc_hash": pcHash,
-		"method":  "oauth",
+		"method":  method,
 	}
 
 	jsonData, _ := json.Marshal(payload)
-	resp, err := http.Post(
-		EndpointAuth+"/exchange",
+	resp, err := httpClient().Post(
+		apiEndpoint(EndpointAuth+"/exchange"),
 		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
-		logError(fmt.Sprintf("Failed to exchange token: %v", err))
-		return
+		return nil, fmt.Errorf("Failed to exchange token: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		logError(fmt.Sprintf("Authentication failed: %s", string(body)))
-		return
+		return nil, fmt.Errorf("Authentication failed: %s", redactBody(body))
 	}
 
-	var authData AuthData
-	if err := json.NewDecoder(resp.Body).Decode(&authData); err != nil {
-		logError(fmt.Sprintf("Invalid response from server: %v", err))
-		return
+	var authResp authResponse
+	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
+		return nil, fmt.Errorf("Invalid response from server: %v", err)
 	}
+	return &authResp, nil
+}
 
-	authData.PCHash = pcHash
-	if err := writeAuth(&authData); err != nil {
-		logError(fmt.Sprintf("Failed to save auth: %v", err))
-		return
+// listenForCallback binds the OAuth callback port. An explicit port is used
+// as-is; otherwise CallbackPort..CallbackPort+10 are tried before letting the
+// OS pick one
+func listenForCallback(port string) (net.Listener, error) {
+	if port != "" {
+		return net.Listen("tcp", ":"+port)
 	}
 
-	logSuccess("Logged in successfully")
-	printDivider()
-	logInfo(fmt.Sprintf("Account: %s", authData.Email))
-	logInfo(fmt.Sprintf("Plan:    %s", authData.Plan))
-	logInfo(fmt.Sprintf("PC ID:   %s", pcHash[:8]+"..."))
-	printDivider()
+	start, _ := strconv.Atoi(CallbackPort)
+	for p := start; p <= start+10; p++ {
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", p))
+		if err == nil {
+			return listener, nil
+		}
+	}
+
+	return net.Listen("tcp", ":0")
 }
 
 // ─── SIGNUP ──────────────────────────────────────────────────────────────────
+// An account is created with a password, or with a magic link: the browser
+// opens the sign-up page, the user clicks the link emailed to them and the
+// redirect lands on the local callback server, as with Gmail login. Either
+// way the account is bound to this machine's PC hash
+
+var (
+	emailPattern    = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,30}$`)
+)
+
+// How long to wait for the magic link to be clicked
+const magicLinkTimeout = 10 * time.Minute
+
+func handleSignup(args []string) {
+	port := ""
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--port" && i+1 < len(args) {
+			port = args[i+1]
+			i++
+		}
+	}
+
+	if port != "" {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			logError(fmt.Sprintf("Invalid port: %s", port))
+			return
+		}
+	}
 
-func handleSignup() {
 	if isLoggedIn() {
 		auth, _ := readAuth()
 		logWarning(fmt.Sprintf("Already logged in as %s", auth.Email))
@@ -292,27 +388,29 @@ func handleSignup() {
 	logInfo("Create new account")
 	fmt.Println()
 
-	email := prompt("Email")
-	password := promptPassword("Password")
-	confirmPassword := promptPassword("Confirm Password")
-
-	if email == "" || password == "" {
-		logError("Email and password cannot be empty")
+	email := strings.TrimSpace(prompt("Email"))
+	if !emailPattern.MatchString(email) {
+		logError(fmt.Sprintf("Invalid email address: %s", email))
 		return
 	}
-
-	if password != confirmPassword {
-		logError("Passwords do not match")
+	username := strings.TrimSpace(prompt("Username"))
+	if !usernamePattern.MatchString(username) {
+		logError("Username must be 3-30 letters, digits, '-' or '_'")
 		return
 	}
 
-	if len(password) < 6 {
-		logError("Password must be at least 6 characters")
+	fmt.Println()
+	fmt.Println("Choose sign-up method:")
+	fmt.Println()
+	fmt.Println("  1. Email & Password")
+	fmt.Println("  2. Magic link (no password, confirm from your inbox)")
+	fmt.Println()
+	choice := strings.TrimSpace(prompt("Enter choice (1 or 2)"))
+	if choice != "1" && choice != "2" {
+		logError("Invalid choice. Please enter 1 or 2")
 		return
 	}
 
-	logInfo("Creating account...")
-
 	// Generate PC hash
 	pcHash, err := generatePCHash()
 	if err != nil {
@@ -320,54 +418,109 @@ func handleSignup() {
 		return
 	}
 
+	var authResp *authResponse
+	if choice == "1" {
+		authResp, err = signupWithPassword(email, username, pcHash)
+	} else {
+		authResp, err = signupWithMagicLink(email, username, pcHash, port)
+	}
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	authData := authResp.AuthData
+	authData.PCHash = pcHash
+	if err := writeAuth(&authData); err != nil {
+		logError(fmt.Sprintf("Failed to save auth: %v", err))
+		return
+	}
+
+	logSuccess("Account created successfully!")
+	printDivider()
+	logInfo(fmt.Sprintf("Account:  %s (%s)", authData.Email, username))
+	logInfo(fmt.Sprintf("Plan:     %s", authData.Plan))
+	if authResp.Credits > 0 {
+		logInfo(fmt.Sprintf("Credits:  %d", authResp.Credits))
+	} else if credits, err := fetchCredits(&authData); err == nil {
+		logInfo(fmt.Sprintf("Credits:  %d", credits.Remaining))
+	}
+	logInfo(fmt.Sprintf("PC ID:    %s", redact(pcHash)))
+	printDivider()
+}
+
+// signupWithPassword creates the account in one request
+func signupWithPassword(email, username, pcHash string) (*authResponse, error) {
+	password := promptPassword("Password")
+	confirmPassword := promptPassword("Confirm Password")
+
+	if password == "" {
+		return nil, fmt.Errorf("Password cannot be empty")
+	}
+	if password != confirmPassword {
+		return nil, fmt.Errorf("Passwords do not match")
+	}
+	if len(password) < 6 {
+		return nil, fmt.Errorf("Password must be at least 6 characters")
+	}
+
+	logInfo("Creating account...")
+
 	// Call signup endpoint
 	payload := map[string]string{
 		"email":    email,
+		"username": username,
 		"password": password,
 		"pc_hash":  pcHash,
 	}
 
 	jsonData, _ := json.Marshal(payload)
-	resp, err := http.Post(
-		EndpointAuth+"/signup",
+	resp, err := httpClient().Post(
+		apiEndpoint(EndpointAuth+"/signup"),
 		"application/json",
 		bytes.NewBuffer(jsonData),
 	)
 	if err != nil {
-		logError(fmt.Sprintf("Network error: %v", err))
-		return
+		return nil, fmt.Errorf("Network error: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 409 {
-		logError("Email already exists. Try logging in instead.")
-		return
+		return nil, fmt.Errorf("An account with %s already exists. Run 'keke login' instead", email)
 	}
 
-	if resp.StatusCode != 200 {
+	if resp.StatusCode != 200 && resp.StatusCode != 201 {
 		body, _ := io.ReadAll(resp.Body)
-		logError(fmt.Sprintf("Signup failed: %s", string(body)))
-		return
+		return nil, fmt.Errorf("Signup failed: %s", redactBody(body))
 	}
 
-	var authData AuthData
-	if err := json.NewDecoder(resp.Body).Decode(&authData); err != nil {
-		logError(fmt.Sprintf("Invalid response from server: %v", err))
-		return
+	var authResp authResponse
+	if err := json.NewDecoder(resp.Body).Decode(&authResp); err != nil {
+		return nil, fmt.Errorf("Invalid response from server: %v", err)
 	}
+	return &authResp, nil
+}
 
-	authData.PCHash = pcHash
-	if err := writeAuth(&authData); err != nil {
-		logError(fmt.Sprintf("Failed to save auth: %v", err))
-		return
+// signupWithMagicLink opens the sign-up page, which emails the link, and
+// waits for the redirect that follows a click on it
+func signupWithMagicLink(email, username, pcHash, port string) (*authResponse, error) {
+	logInfo("Opening browser to create your account...")
+	logInfo(fmt.Sprintf("Then click the link sent to %s (waiting up to %s)", email, magicLinkTimeout))
+
+	code, err := waitForAuthCode(port, "Account created", magicLinkTimeout, func(callbackURL string) string {
+		query := url.Values{}
+		query.Set("redirect", callbackURL)
+		query.Set("email", email)
+		query.Set("username", username)
+		query.Set("method", "magic_link")
+		return apiEndpoint(EndpointAuth+"/signup") + "?" + query.Encode()
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	logSuccess("Account created successfully!")
-	printDivider()
-	logInfo(fmt.Sprintf("Account: %s", authData.Email))
-	logInfo(fmt.Sprintf("Plan:    %s", authData.Plan))
-	logInfo(fmt.Sprintf("Credits: 10 (free tier)"))
-	printDivider()
+	logInfo("Exchanging auth code for token...")
+	return exchangeAuthCode(code, pcHash, "magic_link")
 }
 
 // ─── LOGOUT ──────────────────────────────────────────────────────────────────
@@ -382,15 +535,52 @@ func handleLogout() {
 		logError(fmt.Sprintf("Failed to logout: %v", err))
 		return
 	}
+	os.Remove(globalWhoamiCacheFile())
 
 	logSuccess("Logged out")
 }
 
 // ─── WHOAMI ──────────────────────────────────────────────────────────────────
+// Answers are cached for a minute in ~/.keke/whoami-cache.json so shell
+// prompts can call 'keke whoami --json' cheaply. The cache belongs to the
+// account and machine it was fetched for, and logout deletes it
+
+const whoamiCacheTTL = 60 * time.Second
+
+// WhoamiInfo - what 'keke whoami' reports
+type WhoamiInfo struct {
+	Email     string    `json:"email"`
+	Plan      string    `json:"plan"`
+	Credits   int       `json:"credits"`
+	PCHash    string    `json:"pc_hash"` // redacted
+	UserID    string    `json:"-"`
+	FetchedAt time.Time `json:"-"`
+}
+
+// whoamiCache - whoami-cache.json
+type whoamiCache struct {
+	UserID    string     `json:"user_id"`
+	PCHash    string     `json:"pc_hash"`
+	Info      WhoamiInfo `json:"info"`
+	FetchedAt time.Time  `json:"fetched_at"`
+}
+
+func handleWhoami(args []string) {
+	fresh := false
+	for _, arg := range args {
+		switch arg {
+		case "--fresh":
+			fresh = true
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", arg))
+			logInfo("Usage: keke whoami [--fresh] [--json]")
+			return
+		}
+	}
 
-func handleWhoami() {
 	if !isLoggedIn() {
 		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
 		return
 	}
 
@@ -400,45 +590,94 @@ func handleWhoami() {
 		return
 	}
 
-	// Call server for fresh data
-	resp, err := makeAuthenticatedRequest("GET", EndpointWhoami, nil, auth)
-	if err != nil {
-		logError(fmt.Sprintf("Failed to fetch user info: %v", err))
+	info := readWhoamiCache(auth)
+	if info == nil || fresh {
+		info, err = fetchWhoami(auth)
+		if err != nil {
+			logRequestError("Failed to fetch user info", err)
+			return
+		}
+	}
+
+	if jsonMode {
+		emitJSON(info)
 		return
 	}
+
+	printDivider()
+	logInfo(fmt.Sprintf("Account:  %s", info.Email))
+	logInfo(fmt.Sprintf("Plan:     %s", info.Plan))
+	logInfo(fmt.Sprintf("Credits:  %d", info.Credits))
+	logInfo(fmt.Sprintf("PC ID:    %s", info.PCHash))
+	printDivider()
+	logVerbose(fmt.Sprintf("Fetched %s ago", time.Since(info.FetchedAt).Round(time.Second)))
+}
+
+// fetchWhoami asks the server who is logged in and refreshes the cache
+func fetchWhoami(auth *AuthData) (*WhoamiInfo, error) {
+	resp, err := makeAuthenticatedRequestWithRetry("GET", apiEndpoint(EndpointWhoami), nil, auth)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		logError(fmt.Sprintf("Server error: %s", string(body)))
-		return
+		return nil, fmt.Errorf("server error: %s", redactBody(body))
 	}
 
 	var userData struct {
 		Email   string `json:"email"`
 		Plan    string `json:"plan"`
-		PCHash  string `json:"pc_hash"`
 		Credits int    `json:"credits_remaining"`
 	}
-
 	if err := json.NewDecoder(resp.Body).Decode(&userData); err != nil {
-		logError(fmt.Sprintf("Invalid response: %v", err))
-		return
+		return nil, fmt.Errorf("invalid response: %v", err)
 	}
 
-	printDivider()
-	logInfo(fmt.Sprintf("Account:  %s", userData.Email))
-	logInfo(fmt.Sprintf("Plan:     %s", userData.Plan))
-	logInfo(fmt.Sprintf("Credits:  %d", userData.Credits))
-	logInfo(fmt.Sprintf("PC ID:    %s", auth.PCHash[:8]+"..."))
-	printDivider()
+	info := &WhoamiInfo{
+		Email:     userData.Email,
+		Plan:      userData.Plan,
+		Credits:   userData.Credits,
+		PCHash:    redact(auth.PCHash),
+		FetchedAt: time.Now(),
+	}
+	cache := whoamiCache{UserID: auth.UserID, PCHash: auth.PCHash, Info: *info, FetchedAt: info.FetchedAt}
+	if data, err := json.MarshalIndent(cache, "", "  "); err == nil {
+		os.WriteFile(globalWhoamiCacheFile(), data, 0600)
+	}
+	return info, nil
+}
+
+// readWhoamiCache returns the cached answer if it is recent and was fetched
+// for the account and machine in auth
+func readWhoamiCache(auth *AuthData) *WhoamiInfo {
+	data, err := os.ReadFile(globalWhoamiCacheFile())
+	if err != nil {
+		return nil
+	}
+	var cache whoamiCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil
+	}
+	if cache.UserID != auth.UserID || cache.PCHash != auth.PCHash || time.Since(cache.FetchedAt) > whoamiCacheTTL {
+		return nil
+	}
+	cache.Info.FetchedAt = cache.FetchedAt
+	return &cache.Info
 }
 
 // ─── CREDITS ─────────────────────────────────────────────────────────────────
 
-func handleCredits() {
+func handleCredits(args []string) {
+	if len(args) > 0 && args[0] == "budget" {
+		handleCreditsBudget(args[1:])
+		return
+	}
+
 	if !isLoggedIn() {
 		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
 		return
 	}
 
@@ -448,29 +687,14 @@ func handleCredits() {
 		return
 	}
 
-	// Call server for credit info (all logic on server)
-	resp, err := makeAuthenticatedRequest("GET", EndpointCredits, nil, auth)
+	creditData, err := fetchCredits(auth)
 	if err != nil {
-		logError(fmt.Sprintf("Failed to fetch credits: %v", err))
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		logError(fmt.Sprintf("Server error: %s", string(body)))
+		logRequestError("Failed to fetch credits", err)
 		return
 	}
 
-	var creditData struct {
-		Remaining    int    `json:"remaining"`
-		MonthlyLimit int    `json:"monthly_limit"`
-		ResetDate    string `json:"reset_date"`
-		Plan         string `json:"plan"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&creditData); err != nil {
-		logError(fmt.Sprintf("Invalid response: %v", err))
+	if jsonMode {
+		emitJSON(creditData)
 		return
 	}
 
@@ -478,6 +702,9 @@ func handleCredits() {
 	logInfo(fmt.Sprintf("Credits:  %d / %d", creditData.Remaining, creditData.MonthlyLimit))
 	logInfo(fmt.Sprintf("Plan:     %s", creditData.Plan))
 	logInfo(fmt.Sprintf("Resets:   %s", creditData.ResetDate))
+	if budget := getConfig().Budget; budget > 0 {
+		logInfo(fmt.Sprintf("Budget:   %d per session", budget))
+	}
 	printDivider()
 
 	// Warning if low
@@ -489,6 +716,44 @@ func handleCredits() {
 	}
 }
 
+// fetchCredits asks the server for the credit balance (all logic on server)
+// and caches the answer for offline use by 'keke status'
+func fetchCredits(auth *AuthData) (*CreditInfo, error) {
+	resp, err := makeAuthenticatedRequestWithRetry("GET", apiEndpoint(EndpointCredits), nil, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s", redactBody(body))
+	}
+
+	var creditData CreditInfo
+	if err := json.NewDecoder(resp.Body).Decode(&creditData); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+
+	creditData.FetchedAt = time.Now()
+	if data, err := json.MarshalIndent(creditData, "", "  "); err == nil {
+		os.WriteFile(globalCreditsCacheFile(), data, 0600)
+	}
+
+	return &creditData, nil
+}
+
+// readCachedCredits returns the last balance saved by fetchCredits
+func readCachedCredits() (*CreditInfo, error) {
+	data, err := os.ReadFile(globalCreditsCacheFile())
+	if err != nil {
+		return nil, err
+	}
+	var creditData CreditInfo
+	err = json.Unmarshal(data, &creditData)
+	return &creditData, err
+}
+
 // ─── PC HASH ─────────────────────────────────────────────────────────────────
 
 func generatePCHash() (string, error) {
@@ -555,9 +820,139 @@ func getMacHardwareUUID() (string, error) {
 	return "", fmt.Errorf("UUID not found")
 }
 
+// ─── TOKEN REFRESH ───────────────────────────────────────────────────────────
+
+// Refresh this many seconds before the access token actually expires, so a
+// request started just before expiry does not fail mid-flight
+const tokenRefreshMargin = 60
+
+// refreshTokenIfNeeded refreshes the access token when it is about to expire
+func refreshTokenIfNeeded(auth *AuthData) error {
+	if !tokenExpiring(auth) {
+		return nil
+	}
+	return refreshToken(auth)
+}
+
+func tokenExpiring(auth *AuthData) bool {
+	return auth.ExpiresAt != 0 && time.Now().Unix() >= auth.ExpiresAt-tokenRefreshMargin
+}
+
+// Concurrent requests (signal batches) each hold their own copy of the auth,
+// so renewals take turns: the first refreshes, the rest reuse its saved token
+var renewMu sync.Mutex
+
+// renewAuth replaces stale, the expiring or rejected token of auth: with
+// the token in ~/.keke/auth.json when another request already renewed it,
+// else by a refresh, else by logging in again
+func renewAuth(auth *AuthData, stale string) error {
+	renewMu.Lock()
+	defer renewMu.Unlock()
+
+	if saved, err := readAuth(); err == nil && saved.AccessToken != "" && saved.AccessToken != stale {
+		*auth = *saved
+		return nil
+	}
+	if err := refreshToken(auth); err != nil {
+		logWarning(fmt.Sprintf("Token refresh failed: %v", err))
+		return promptReLogin(auth)
+	}
+	return nil
+}
+
+// refreshToken swaps the access token for a new one using the stored refresh
+// token, and saves the result to ~/.keke/auth.json
+func refreshToken(auth *AuthData) error {
+	if auth.RefreshToken == "" {
+		return fmt.Errorf("no refresh token stored")
+	}
+
+	payload := map[string]string{
+		"refresh_token": auth.RefreshToken,
+		"pc_hash":       auth.PCHash,
+	}
+
+	jsonData, _ := json.Marshal(payload)
+	resp, err := httpClient().Post(
+		apiEndpoint(EndpointRefresh),
+		"application/json",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return fmt.Errorf("refresh token has expired")
+	}
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("refresh rejected: %s", redactBody(body))
+	}
+
+	var refreshed AuthData
+	if err := json.NewDecoder(resp.Body).Decode(&refreshed); err != nil {
+		return fmt.Errorf("invalid response from server: %v", err)
+	}
+
+	// Server may omit fields that did not change
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = auth.RefreshToken
+	}
+	if refreshed.Email == "" {
+		refreshed.Email = auth.Email
+	}
+	if refreshed.Plan == "" {
+		refreshed.Plan = auth.Plan
+	}
+	if refreshed.UserID == "" {
+		refreshed.UserID = auth.UserID
+	}
+	refreshed.PCHash = auth.PCHash
+
+	if err := writeAuth(&refreshed); err != nil {
+		return fmt.Errorf("failed to save auth: %v", err)
+	}
+
+	*auth = refreshed
+	return nil
+}
+
+// promptReLogin asks the user to log in again after a failed refresh and
+// loads the new credentials into auth
+func promptReLogin(auth *AuthData) error {
+	logWarning("Your session has expired")
+	// --yes runs unattended, with no one to complete a browser login
+	if assumeYes || !promptYesNo("Log in again now? (y/n)") {
+		return errSessionExpired
+	}
+
+	if err := os.Remove(globalAuthFile()); err != nil {
+		return fmt.Errorf("failed to clear expired session: %v", err)
+	}
+
+	handleLogin(nil)
+
+	fresh, err := readAuth()
+	if err != nil {
+		return fmt.Errorf("login did not complete")
+	}
+
+	*auth = *fresh
+	return nil
+}
+
 // ─── HTTP HELPERS ────────────────────────────────────────────────────────────
 
 func makeAuthenticatedRequest(method, url string, body io.Reader, auth *AuthData) (*http.Response, error) {
+	if tokenExpiring(auth) {
+		if err := renewAuth(auth, auth.AccessToken); err != nil {
+			return nil, err
+		}
+	}
+
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
@@ -567,8 +962,141 @@ func makeAuthenticatedRequest(method, url string, body io.Reader, auth *AuthData
 	req.Header.Set("X-PC-Hash", auth.PCHash)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	return client.Do(req)
+	logVerbose(fmt.Sprintf("%s %s (%s)", method, url, formatBytes(max(req.ContentLength, 0))))
+	logRequestHeaders(req)
+	start := time.Now()
+	resp, err := clientFor(url).Do(req)
+	if err != nil {
+		logVerbose(fmt.Sprintf("%s %s failed after %s: %v", method, url, time.Since(start).Round(time.Millisecond), err))
+		return nil, err
+	}
+	size := "size unknown"
+	if resp.ContentLength >= 0 {
+		size = formatBytes(resp.ContentLength)
+	}
+	logVerbose(fmt.Sprintf("%s %s → %s (%s, %s)", method, url, resp.Status, size, time.Since(start).Round(time.Millisecond)))
+	return resp, nil
+}
+
+// makeAuthenticatedRequestWithRetry retries transient failures (429/5xx,
+// dropped or refused connections) with exponential backoff, or after the
+// server's Retry-After when it sends one. 402 is never retried; 401 is retried
+// once after refreshing the token, then reported as errSessionExpired. The
+// body is buffered so it can be resent; Ctrl+C while waiting aborts the retry
+func makeAuthenticatedRequestWithRetry(method, url string, body io.Reader, auth *AuthData) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = data
+	}
+
+	retry := getRetryConfig()
+	delay := retry.BaseDelay
+	reauthenticated := false
+
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if payload != nil {
+			reqBody = bytes.NewReader(payload)
+		}
+
+		resp, err := makeAuthenticatedRequest(method, url, reqBody, auth)
+
+		// A rejected token is refreshed (or the user logs in again) and the
+		// request resent, once
+		if err == nil && resp.StatusCode == 401 {
+			resp.Body.Close()
+			if reauthenticated {
+				return nil, errSessionExpired
+			}
+			reauthenticated = true
+			if err := renewAuth(auth, auth.AccessToken); err != nil {
+				return nil, errSessionExpired
+			}
+			attempt--
+			continue
+		}
+
+		reason := ""
+		var opErr *net.OpError
+		if err != nil && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)) {
+			reason = "connection dropped"
+		} else if err != nil && errors.As(err, &opErr) && opErr.Op == "dial" {
+			reason = "could not connect"
+		} else if err == nil && retry.retryable(resp.StatusCode) {
+			reason = fmt.Sprintf("server returned %d", resp.StatusCode)
+		}
+
+		if reason == "" || attempt >= retry.MaxAttempts {
+			return resp, err
+		}
+
+		wait := jitter(delay)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = min(after, retry.MaxDelay)
+			}
+			resp.Body.Close()
+		}
+
+		logWarning(fmt.Sprintf("Request failed (%s), retrying in %.1fs (attempt %d/%d)",
+			reason, wait.Seconds(), attempt+1, retry.MaxAttempts))
+		if err := sleepInterruptible(wait); err != nil {
+			return nil, err
+		}
+
+		delay *= 2
+		if delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+}
+
+func (r RetryConfig) retryable(status int) bool {
+	for _, s := range r.RetryableStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header, given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// jitter spreads d by ±20% so clients don't retry in lockstep
+func jitter(d time.Duration) time.Duration {
+	return time.Duration(float64(d) * (0.8 + 0.4*mathrand.Float64()))
+}
+
+// sleepInterruptible waits for d, returning early with an error on Ctrl+C
+func sleepInterruptible(d time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("interrupted while waiting to retry")
+	}
 }
 
 func openBrowser(url string) {
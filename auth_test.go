@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newRefreshServer answers token refreshes with status, and AI requests
+// with 200 for the refreshed token only
+func newRefreshServer(t *testing.T, status int) *int {
+	t.Helper()
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointRefresh:
+			refreshes++
+			var payload map[string]string
+			json.NewDecoder(r.Body).Decode(&payload)
+			if status != http.StatusOK || payload["refresh_token"] != "refresh-1" {
+				w.WriteHeader(status)
+				return
+			}
+			json.NewEncoder(w).Encode(AuthData{
+				AccessToken:  "access-2",
+				RefreshToken: "refresh-2",
+				ExpiresAt:    time.Now().Add(time.Hour).Unix(),
+			})
+		default:
+			if r.Header.Get("Authorization") != "Bearer access-2" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"ok": true}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("KEKE_API_BASE_URL", server.URL)
+	loadedConfig = nil
+	return &refreshes
+}
+
+func expiredAuth() *AuthData {
+	return &AuthData{
+		AccessToken:  "access-1",
+		RefreshToken: "refresh-1",
+		Email:        "dev@example.com",
+		PCHash:       "pc",
+		ExpiresAt:    time.Now().Add(-time.Minute).Unix(),
+	}
+}
+
+func TestRefreshTokenIfNeeded(t *testing.T) {
+	newTestProject(t)
+	refreshes := newRefreshServer(t, http.StatusOK)
+
+	auth := expiredAuth()
+	if err := refreshTokenIfNeeded(auth); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if *refreshes != 1 {
+		t.Errorf("got %d refresh requests, want 1", *refreshes)
+	}
+	if auth.AccessToken != "access-2" || auth.RefreshToken != "refresh-2" {
+		t.Errorf("tokens not replaced: %+v", auth)
+	}
+	if auth.Email != "dev@example.com" || auth.PCHash != "pc" {
+		t.Errorf("fields the server omitted were lost: %+v", auth)
+	}
+
+	saved, err := readAuth()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken != "access-2" {
+		t.Errorf("saved access token = %q, want access-2", saved.AccessToken)
+	}
+
+	// A token that is still valid is left alone
+	if err := refreshTokenIfNeeded(auth); err != nil || *refreshes != 1 {
+		t.Errorf("valid token refreshed again (err %v, %d requests)", err, *refreshes)
+	}
+}
+
+func TestRequestRefreshesExpiredToken(t *testing.T) {
+	newTestProject(t)
+	newRefreshServer(t, http.StatusOK)
+
+	resp, err := makeAuthenticatedRequestWithRetry("GET", apiEndpoint(EndpointWhoami), nil, expiredAuth())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d after refresh, want 200", resp.StatusCode)
+	}
+}
+
+func TestExpiredRefreshToken(t *testing.T) {
+	newTestProject(t)
+	newRefreshServer(t, http.StatusUnauthorized)
+
+	if err := refreshTokenIfNeeded(expiredAuth()); err == nil {
+		t.Fatal("refresh with an expired refresh token succeeded")
+	}
+
+	// Under --yes the request fails instead of starting an interactive login
+	if err := writeAuth(expiredAuth()); err != nil {
+		t.Fatal(err)
+	}
+	_, err := makeAuthenticatedRequestWithRetry("GET", apiEndpoint(EndpointWhoami), nil, expiredAuth())
+	if !errors.Is(err, errSessionExpired) {
+		t.Errorf("err = %v, want errSessionExpired", err)
+	}
+	if _, err := readAuth(); err != nil {
+		t.Errorf("stored auth was removed: %v", err)
+	}
+}
+
+func TestConcurrentRequestsRefreshOnce(t *testing.T) {
+	newTestProject(t)
+	refreshes := newRefreshServer(t, http.StatusOK)
+
+	// A token the server rejects before its expiry
+	auth := expiredAuth()
+	auth.ExpiresAt = time.Now().Add(time.Hour).Unix()
+	if err := writeAuth(auth); err != nil {
+		t.Fatal(err)
+	}
+
+	url := apiEndpoint(EndpointWhoami) // loads the config before the goroutines
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			callAuth := *auth
+			resp, err := makeAuthenticatedRequestWithRetry("GET", url, nil, &callAuth)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					err = fmt.Errorf("status %d", resp.StatusCode)
+				}
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("request %d: %v", i, err)
+		}
+	}
+	if *refreshes != 1 {
+		t.Errorf("got %d refresh requests, want 1", *refreshes)
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─── SIGNAL BACKTEST ─────────────────────────────────────────────────────────
+// 'keke signal backtest EURUSD 2025-01-01 2025-06-30' asks the backend to
+// replay the signal model over past prices and shows how its trades went,
+// with an equity curve of the cumulative return
+
+// HistoricalTrade - one trade the model would have taken
+type HistoricalTrade struct {
+	Date      string  `json:"date"`
+	Direction string  `json:"direction"` // BUY or SELL
+	Entry     float64 `json:"entry"`
+	Exit      float64 `json:"exit"`    // where the trade actually closed
+	Outcome   string  `json:"outcome"` // win, loss or breakeven
+}
+
+// BacktestResult - the /signal-backtest response
+type BacktestResult struct {
+	ID          string            `json:"id,omitempty"` // matches TradeSignal.BacktestID
+	Symbol      string            `json:"symbol"`
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Timeframe   string            `json:"timeframe"`
+	Trades      []HistoricalTrade `json:"trades"`
+	WinRate     float64           `json:"win_rate"`     // percent
+	AvgRR       float64           `json:"avg_rr"`       // average risk/reward
+	MaxDrawdown float64           `json:"max_drawdown"` // percent
+	CreditsUsed int               `json:"credits_used"`
+}
+
+func handleSignalBacktest(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	timeframe := getConfig().DefaultTimeframe
+	provider := aiProvider()
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--timeframe" && i+1 < len(args) {
+			timeframe = args[i+1]
+			i++
+		} else {
+			positional = append(positional, args[i])
+		}
+	}
+
+	if len(positional) != 3 {
+		logError("Usage: keke signal backtest <PAIR> <from YYYY-MM-DD> <to YYYY-MM-DD> [--timeframe 1H|4H|1D] [--provider P]")
+		logInfo("Example: keke signal backtest EURUSD 2025-01-01 2025-06-30")
+		return
+	}
+	timeframe, err := normalizeTimeframe(timeframe)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	symbol := strings.ToUpper(positional[0])
+	from, err := time.Parse("2006-01-02", positional[1])
+	if err != nil {
+		logError(fmt.Sprintf("Invalid start date: %s (use YYYY-MM-DD)", positional[1]))
+		return
+	}
+	to, err := time.Parse("2006-01-02", positional[2])
+	if err != nil {
+		logError(fmt.Sprintf("Invalid end date: %s (use YYYY-MM-DD)", positional[2]))
+		return
+	}
+	if !to.After(from) {
+		logError("The end date must be after the start date")
+		return
+	}
+	if to.After(time.Now()) {
+		logError("The end date cannot be in the future")
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("Backtesting %s on %s from %s to %s...", symbol, timeframe, positional[1], positional[2]))
+	result, err := getBacktest(symbol, positional[1], positional[2], timeframe, provider, auth)
+	if err != nil {
+		logRequestError("Backtest failed", err)
+		return
+	}
+
+	if jsonMode {
+		emitJSON(result)
+		return
+	}
+	displayBacktest(result)
+}
+
+// getBacktest posts the backtest request to the backend
+func getBacktest(symbol, from, to, timeframe, provider string, auth *AuthData) (*BacktestResult, error) {
+	payload := map[string]interface{}{
+		"symbol":    symbol,
+		"from":      from,
+		"to":        to,
+		"timeframe": timeframe,
+	}
+	if provider != "" {
+		payload["provider"] = provider
+	}
+
+	jsonData, _ := json.Marshal(payload)
+	resp, err := makeAuthenticatedRequestWithRetry("POST", apiEndpoint(EndpointBacktest), bytes.NewBuffer(jsonData), auth)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 402 {
+		return nil, errInsufficientCredits
+	}
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error: %s", redactBody(body))
+	}
+
+	var result BacktestResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	if result.Symbol == "" {
+		result.Symbol, result.From, result.To, result.Timeframe = symbol, from, to, timeframe
+	}
+	return &result, nil
+}
+
+func displayBacktest(result *BacktestResult) {
+	fmt.Println()
+	fmt.Printf("%s%sBacktest %s%s %s(%s, %s to %s)%s\n", bold, magenta, result.Symbol, reset, dim, result.Timeframe, result.From, result.To, reset)
+	if result.ID != "" {
+		fmt.Printf("%sBacktest ID: %s%s\n", dim, result.ID, reset)
+	}
+	printDivider()
+
+	if len(result.Trades) == 0 {
+		logInfo("No trades in this period")
+		return
+	}
+
+	wins, losses := 0, 0
+	for _, trade := range result.Trades {
+		switch trade.Outcome {
+		case "win":
+			wins++
+		case "loss":
+			losses++
+		}
+	}
+	fmt.Printf("Trades:        %d (%s%d won%s, %s%d lost%s)\n", len(result.Trades), green, wins, reset, red, losses, reset)
+	fmt.Printf("Win rate:      %.1f%%\n", result.WinRate)
+	fmt.Printf("Avg R:R:       1:%.2f\n", result.AvgRR)
+	fmt.Printf("Max drawdown:  %s%.1f%%%s\n", red, result.MaxDrawdown, reset)
+
+	curve := equityCurve(result.Trades)
+	fmt.Printf("Total return:  %+.2f%%\n", curve[len(curve)-1])
+	fmt.Println()
+
+	fmt.Printf("%s━━━ Trades ━━━%s\n", dim, reset)
+	for _, trade := range result.Trades {
+		outcomeColor := yellow
+		switch trade.Outcome {
+		case "win":
+			outcomeColor = green
+		case "loss":
+			outcomeColor = red
+		}
+		fmt.Printf("%-12s %-4s %12.5f → %-12.5f %s%s%s\n", trade.Date, trade.Direction, trade.Entry, trade.Exit, outcomeColor, trade.Outcome, reset)
+	}
+	fmt.Println()
+
+	fmt.Printf("%s━━━ Equity Curve (cumulative %% return) ━━━%s\n", dim, reset)
+	for _, line := range asciiChart(curve, terminalWidth()-12, 8) {
+		fmt.Println(line)
+	}
+	fmt.Println()
+	fmt.Println("This replays AI predictions on past prices. It is NOT financial advice.")
+}
+
+// equityCurve returns the cumulative percent return after each trade,
+// starting from 0
+func equityCurve(trades []HistoricalTrade) []float64 {
+	curve := []float64{0}
+	total := 0.0
+	for _, trade := range trades {
+		if trade.Entry == 0 {
+			continue
+		}
+		change := (trade.Exit - trade.Entry) / trade.Entry * 100
+		if trade.Direction == "SELL" {
+			change = -change
+		}
+		total += change
+		curve = append(curve, total)
+	}
+	return curve
+}
+
+// asciiChart plots values on a grid of width columns and height rows,
+// stretching or sampling them to fit, with the top and bottom labelled
+func asciiChart(values []float64, width, height int) []string {
+	if width < 10 {
+		width = 10
+	}
+
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		low, high = math.Min(low, v), math.Max(high, v)
+	}
+	if high == low {
+		high, low = high+1, low-1
+	}
+
+	grid := make([][]rune, height)
+	for row := range grid {
+		grid[row] = []rune(strings.Repeat(" ", width))
+	}
+	rowOf := func(v float64) int {
+		return int(math.Round((high - v) / (high - low) * float64(height-1)))
+	}
+	if low < 0 && high > 0 {
+		zero := rowOf(0)
+		for col := range grid[zero] {
+			grid[zero][col] = '·'
+		}
+	}
+	for col := 0; col < width; col++ {
+		v := values[col*(len(values)-1)/max(width-1, 1)]
+		grid[rowOf(v)][col] = '*'
+	}
+
+	lines := make([]string, height)
+	for row := range grid {
+		label := ""
+		switch {
+		case row == 0:
+			label = fmt.Sprintf("%+.1f%%", high)
+		case row == height-1:
+			label = fmt.Sprintf("%+.1f%%", low)
+		}
+		lines[row] = fmt.Sprintf("%9s │%s", label, string(grid[row]))
+	}
+	return lines
+}
+
+// terminalWidth reads $COLUMNS, falling back to 80
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 20 {
+		return n
+	}
+	return 80
+}
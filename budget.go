@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ─── CREDIT BUDGET ───────────────────────────────────────────────────────────
+// A per-session credit budget (--budget N, else the max_credits_per_session
+// config) pauses the AI loops when the next step would take a run past it,
+// and asks before any more tool results go back to the AI
+
+var budgetFlag int
+
+func sessionBudget() int {
+	if budgetFlag > 0 {
+		return budgetFlag
+	}
+	return getConfig().Budget
+}
+
+// overBudget reports whether another step, costing about as much as the last
+// one (next), would take spent past allowed, and if so asks the user whether
+// to allow another budget's worth. allowed is raised when they agree; with
+// --yes or without a terminal to ask on the loop stops
+func overBudget(spent, next int, allowed *int, budget int) bool {
+	if budget <= 0 || spent+next <= *allowed {
+		return false
+	}
+	fmt.Fprintln(promptOutput())
+	if spent >= *allowed {
+		logWarning(fmt.Sprintf("Credit budget reached: %d credits used this session (budget %d)", spent, budget))
+	} else {
+		logWarning(fmt.Sprintf("The next step would pass the credit budget: %d used, about %d per step, budget %d", spent, next, budget))
+	}
+	// --yes must not answer this one: the budget is what limits unattended runs
+	if !assumeYes && isTerminal(os.Stdin) && promptYesNo(fmt.Sprintf("Continue for up to %d more credits? (y/n)", budget)) {
+		*allowed = spent + budget
+		return false
+	}
+	logInfo("Stopped before sending more work to the AI. Raise the limit with --budget N or 'keke credits budget set N'")
+	return true
+}
+
+// warnBudgetAboveBalance compares budget with the account's remaining
+// credits before a loop starts. Nothing is said when the balance is unknown
+func warnBudgetAboveBalance(budget int, auth *AuthData) {
+	if budget <= 0 {
+		return
+	}
+	credits, err := fetchCredits(auth)
+	if err != nil {
+		return
+	}
+	if credits.Remaining < budget {
+		logWarning(fmt.Sprintf("Budget of %d credits is more than the %d credits left this month", budget, credits.Remaining))
+	}
+}
+
+// 'keke credits budget show|set <n>'
+func handleCreditsBudget(args []string) {
+	if len(args) == 0 || args[0] == "show" {
+		budget := getConfig().Budget
+		if jsonMode {
+			emitJSON(map[string]int{"max_credits_per_session": budget})
+			return
+		}
+		if budget <= 0 {
+			logInfo("No credit budget set. Set one with: keke credits budget set <n>")
+			return
+		}
+		logInfo(fmt.Sprintf("Credit budget: %d credits per session", budget))
+		return
+	}
+
+	if args[0] != "set" || len(args) != 2 {
+		logError("Usage: keke credits budget [show | set <n>]")
+		return
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 {
+		logError(fmt.Sprintf("Invalid budget: %s (a number of credits, 0 for no limit)", args[1]))
+		return
+	}
+
+	if err := saveConfigValue("max_credits_per_session", strconv.Itoa(n)); err != nil {
+		logError(err.Error())
+		return
+	}
+	if n == 0 {
+		logSuccess("Credit budget removed")
+	} else {
+		logSuccess(fmt.Sprintf("Credit budget set to %d credits per session", n))
+	}
+}
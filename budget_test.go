@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+// Both limits stop the loop under --yes instead of extending themselves
+func TestOverBudget(t *testing.T) {
+	assumeYes = true
+	defer func() { assumeYes = false }()
+
+	tests := []struct {
+		spent, next, allowed, budget int
+		want                         bool
+	}{
+		{0, 10, 50, 0, false},   // no budget
+		{30, 10, 50, 50, false}, // room for the next step
+		{45, 10, 50, 50, true},  // next step would pass it
+		{50, 5, 50, 50, true},   // reached
+	}
+	for _, tt := range tests {
+		allowed := tt.allowed
+		if got := overBudget(tt.spent, tt.next, &allowed, tt.budget); got != tt.want {
+			t.Errorf("overBudget(%d, %d, %d, %d) = %v, want %v", tt.spent, tt.next, tt.allowed, tt.budget, got, tt.want)
+		}
+		if allowed != tt.allowed {
+			t.Errorf("overBudget(%d, %d, %d, %d) raised allowed to %d under --yes", tt.spent, tt.next, tt.allowed, tt.budget, allowed)
+		}
+	}
+}
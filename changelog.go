@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ─── CHANGELOG ───────────────────────────────────────────────────────────────
+// Files written, commands run and commits made by the AI are appended to
+// .keke/changelog.md, one Markdown list item each:
+//
+//	- **2026-01-31T15:04:05Z** write_file `src/main.go`: Updated (1.2 KB)
+//
+// 'keke changelog' prints the entries, --since filters them
+
+const changelogEntryPrefix = "- **"
+
+// ChangelogEntry - one line of .keke/changelog.md
+type ChangelogEntry struct {
+	Time        time.Time `json:"time"`
+	Action      string    `json:"action"` // write_file, execute_command or git_commit
+	Target      string    `json:"target"` // path, command or first line of a commit message
+	Description string    `json:"description"`
+}
+
+// appendChangelog adds entry to the changelog. Failures are reported but
+// never stop the action; dry runs change nothing and are not logged
+func appendChangelog(entry ChangelogEntry) {
+	if dryRun || !isProjectInitialized() {
+		return
+	}
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	f, err := os.OpenFile(projectChangelogFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		logWarning(fmt.Sprintf("Failed to update changelog: %v", err))
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, entry.String())
+}
+
+func (e ChangelogEntry) String() string {
+	target := strings.ReplaceAll(firstLine(e.Target), "`", "'")
+	return fmt.Sprintf("%s%s** %s `%s`: %s", changelogEntryPrefix, e.Time.UTC().Format(time.RFC3339), e.Action, target, e.Description)
+}
+
+// readChangelog parses the entries of the changelog, skipping the header
+// and anything edited by hand
+func readChangelog() ([]ChangelogEntry, error) {
+	data, err := os.ReadFile(projectChangelogFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []ChangelogEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, changelogEntryPrefix) {
+			continue
+		}
+		stamp, rest, ok := strings.Cut(strings.TrimPrefix(line, changelogEntryPrefix), "** ")
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, stamp)
+		if err != nil {
+			continue
+		}
+		action, rest, _ := strings.Cut(rest, " `")
+		target, description, _ := strings.Cut(rest, "`: ")
+		entries = append(entries, ChangelogEntry{Time: t, Action: action, Target: target, Description: description})
+	}
+	return entries, nil
+}
+
+// ─── CHANGELOG COMMAND ───────────────────────────────────────────────────────
+
+func handleChangelog(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	var since time.Time
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--since":
+			if i+1 >= len(args) {
+				logError("--since needs a time (2026-01-31, 2026-01-31T15:04:05Z or an age like 7d)")
+				return
+			}
+			t, err := parseSince(args[i+1])
+			if err != nil {
+				logError(err.Error())
+				return
+			}
+			since = t
+			i++
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke changelog [--since <time>]")
+			return
+		}
+	}
+
+	entries, err := readChangelog()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read changelog: %v", err))
+		return
+	}
+
+	var matches []ChangelogEntry
+	for _, e := range entries {
+		if !e.Time.Before(since) {
+			matches = append(matches, e)
+		}
+	}
+
+	if jsonMode {
+		emitJSON(matches)
+		return
+	}
+
+	if len(matches) == 0 {
+		logInfo("No matching changelog entries")
+		return
+	}
+	printDivider()
+	for _, e := range matches {
+		fmt.Printf("%s  %-15s %s%s%s  %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Action, bold, e.Target, reset, e.Description)
+	}
+	printDivider()
+	logInfo(fmt.Sprintf("Showing %d of %d entries (%s)", len(matches), len(entries), projectChangelogFile()))
+}
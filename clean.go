@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ─── CLEAN ───────────────────────────────────────────────────────────────────
+// 'keke clean' deletes automatic snapshots and saved sessions older than
+// --older-than days. Named snapshots are kept; --dry-run only reports.
+// --compress also gzips snapshots saved before compression was added
+
+func handleClean(args []string) {
+	days := 7
+	compress := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--compress":
+			compress = true
+		case "--older-than":
+			if i+1 >= len(args) {
+				logError("--older-than needs a number of days")
+				return
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				logError(fmt.Sprintf("Invalid --older-than: %s", args[i+1]))
+				return
+			}
+			days = n
+			i++
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke clean [--older-than <days>] [--compress] [--dry-run]")
+			return
+		}
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	verb := "Deleted"
+	if dryRun {
+		verb = "[DRY RUN] Would delete"
+	}
+
+	// Snapshots of this project
+	snapCount, snapBytes := 0, int64(0)
+	if isProjectInitialized() {
+		snapshots, _ := loadSnapshots()
+		for _, snaps := range snapshots {
+			for _, snap := range snaps {
+				if snap.Name != "" {
+					continue // named snapshots are kept until deleted by hand
+				}
+				taken, err := parseSnapshotTime(snap.Timestamp)
+				if err != nil || !taken.Before(cutoff) {
+					continue
+				}
+				if !dryRun {
+					if err := os.Remove(snap.Path); err != nil {
+						logWarning(fmt.Sprintf("Failed to delete %s: %v", snap.SnapshotFile, err))
+						continue
+					}
+				}
+				snapCount++
+				snapBytes += snap.Size
+			}
+		}
+	}
+
+	// Sessions of every project
+	sessionCount, sessionBytes := 0, int64(0)
+	sessions, _ := listSessions()
+	for _, session := range sessions {
+		if !session.UpdatedAt.Before(cutoff) {
+			continue
+		}
+		path := sessionFile(session.ID)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				logWarning(fmt.Sprintf("Failed to delete session %s: %v", session.ID, err))
+				continue
+			}
+		}
+		sessionCount++
+		sessionBytes += info.Size()
+	}
+
+	if compress && isProjectInitialized() {
+		compressSnapshots()
+	}
+
+	if snapCount == 0 && sessionCount == 0 {
+		logInfo(fmt.Sprintf("Nothing older than %d days to clean", days))
+		return
+	}
+
+	logSuccess(fmt.Sprintf("%s %d snapshots (%s) and %d sessions (%s)",
+		verb, snapCount, formatBytes(snapBytes), sessionCount, formatBytes(sessionBytes)))
+	if !dryRun {
+		logInfo(fmt.Sprintf("Freed %s", formatBytes(snapBytes+sessionBytes)))
+	}
+}
+
+// compressSnapshots gzips every uncompressed snapshot left in the project
+func compressSnapshots() {
+	snapshots, _ := loadSnapshots()
+
+	count, before, after := 0, int64(0), int64(0)
+	for _, snaps := range snapshots {
+		for _, snap := range snaps {
+			if snap.Compressed {
+				continue
+			}
+			if dryRun {
+				logInfo(fmt.Sprintf("[DRY RUN] Would compress %s", snap.SnapshotFile))
+				count++
+				continue
+			}
+			size, err := compressSnapshot(snap)
+			if err != nil {
+				logWarning(fmt.Sprintf("Failed to compress %s: %v", snap.SnapshotFile, err))
+				continue
+			}
+			count++
+			before += snap.Size
+			after += size
+		}
+	}
+
+	switch {
+	case count == 0:
+		logInfo("All snapshots are already compressed")
+	case dryRun:
+		logInfo(fmt.Sprintf("[DRY RUN] Would compress %d snapshots", count))
+	default:
+		logSuccess(fmt.Sprintf("Compressed %d snapshots (%s → %s)", count, formatBytes(before), formatBytes(after)))
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ─── CODE BLOCKS ─────────────────────────────────────────────────────────────
+// Files normally arrive as write_file actions. When a model answers with
+// fenced code instead, the blocks are written through the same write_file
+// path (permission, snapshot, --dry-run). A block's file name comes from,
+// in order: the fence line (```go main.go), a "// filepath: main.go" comment
+// on its first line, a path on the line above the fence (### src/main.go,
+// **main.go**, `main.go`, File: main.go), or the default name for its
+// language (```go writes main.go). A default name only creates a new file:
+// it never overwrites one the reply did not name
+
+// CodeBlock - a fenced block of a reply and the file it belongs in
+type CodeBlock struct {
+	Path     string
+	Language string
+	Content  string
+	Default  bool // Path is the language's default name, not one from the reply
+}
+
+// Default file names of language-only fences. Shell, JSON and plain text
+// fences are left out: they usually hold commands or output, not files
+var defaultCodeFileNames = map[string]string{
+	"go":         "main.go",
+	"python":     "main.py",
+	"py":         "main.py",
+	"javascript": "index.js",
+	"js":         "index.js",
+	"typescript": "index.ts",
+	"ts":         "index.ts",
+	"jsx":        "App.jsx",
+	"tsx":        "App.tsx",
+	"html":       "index.html",
+	"css":        "styles.css",
+	"rust":       "main.rs",
+	"java":       "Main.java",
+	"c":          "main.c",
+	"cpp":        "main.cpp",
+	"ruby":       "main.rb",
+	"php":        "index.php",
+	"sql":        "schema.sql",
+	"dockerfile": "Dockerfile",
+	"makefile":   "Makefile",
+}
+
+var (
+	// A file name with an extension, or an extensionless build file
+	codePathPattern = regexp.MustCompile(`^[\w.-]*[\w-]+(/[\w.-]+)*\.\w+$|^(\S+/)?(Makefile|Dockerfile)$`)
+	// "// filepath: src/main.go", "# file: app.py", "<!-- path: index.html -->"
+	filepathCommentPattern = regexp.MustCompile(`^\s*(//|#|--|/\*|<!--)\s*(?i:filepath|filename|file|path):\s*(\S+?)\s*(\*/|-->)?\s*$`)
+	// Heading, quote and list markers: "### ", "> ", "- ", "1. "
+	listMarkerPattern = regexp.MustCompile(`^(#+|>|[-*]|\d+\.)\s+`)
+	// "File: main.go", "Filename: main.go"
+	fileLabelPattern = regexp.MustCompile(`^(?i:file|filename|path|filepath):\s*`)
+)
+
+// extractCodeBlocks returns the fenced blocks of message that name a file,
+// or whose language has a default one. A later block for the same file
+// replaces an earlier one
+func extractCodeBlocks(message string) []CodeBlock {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+
+	var blocks []CodeBlock
+	index := map[string]int{}
+	for i := 0; i < len(lines); i++ {
+		fence := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(fence, "```") {
+			continue
+		}
+
+		// Find the closing fence; an unterminated block is dropped
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != "```" {
+			end++
+		}
+		if end == len(lines) {
+			break
+		}
+		body := lines[i+1 : end]
+
+		block := CodeBlock{}
+		info := strings.Fields(strings.TrimPrefix(fence, "```"))
+		if len(info) > 0 {
+			block.Language = strings.ToLower(info[0])
+		}
+		switch {
+		case len(info) > 1 && isCodePath(info[1]):
+			block.Path = info[1]
+		case len(info) == 1 && defaultCodeFileNames[block.Language] == "" && isCodePath(info[0]):
+			block.Path, block.Language = info[0], ""
+		case len(body) > 0 && filepathCommentPattern.MatchString(body[0]):
+			block.Path = filepathCommentPattern.FindStringSubmatch(body[0])[2]
+			body = body[1:]
+		default:
+			block.Path = pathAboveFence(lines[:i])
+		}
+		if block.Path == "" && len(info) == 1 {
+			block.Path = defaultCodeFileNames[block.Language]
+			block.Default = block.Path != ""
+		}
+		i = end
+		if block.Path == "" {
+			continue
+		}
+
+		block.Path = filepath.Clean(block.Path)
+		block.Content = strings.Join(body, "\n") + "\n"
+		if n, ok := index[block.Path]; ok {
+			blocks[n] = block
+			continue
+		}
+		index[block.Path] = len(blocks)
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// pathAboveFence returns the file named on the last non-empty line before a
+// fence, such as "### src/main.go" or "**File: main.go**", or ""
+func pathAboveFence(before []string) string {
+	for i := len(before) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(before[i])
+		if line == "" {
+			continue
+		}
+		line = listMarkerPattern.ReplaceAllString(line, "")
+		line = strings.Trim(line, "*_` ")
+		line = fileLabelPattern.ReplaceAllString(line, "")
+		line = strings.Trim(strings.TrimSuffix(strings.Trim(line, "*_` "), ":"), "*_` ")
+		if isCodePath(line) {
+			return line
+		}
+		return ""
+	}
+	return ""
+}
+
+// isCodePath reports whether s looks like a relative file path
+func isCodePath(s string) bool {
+	return codePathPattern.MatchString(s) && !strings.Contains(s, "://")
+}
+
+// extractAndWriteCodeBlocks writes the files of a reply that has code blocks
+// but no write_file actions, and records them in session. It returns how
+// many blocks it tried to write. A block with only a default name is
+// skipped when that file already exists
+func extractAndWriteCodeBlocks(message string, session *SessionData) int {
+	if readOnlyMode {
+		return 0
+	}
+	var blocks []CodeBlock
+	for _, block := range extractCodeBlocks(message) {
+		if block.Default {
+			if _, err := os.Stat(block.Path); !os.IsNotExist(err) {
+				logWarning(fmt.Sprintf("Skipped a %s code block: it names no file and %s already exists", block.Language, block.Path))
+				continue
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return 0
+	}
+
+	logInfo(fmt.Sprintf("The reply has %d code block(s) but no file actions, writing them:", len(blocks)))
+	for _, block := range blocks {
+		action := Action{Type: "write_file", Path: block.Path, Content: block.Content}
+		result := executeAction(action)
+		session.recordAction(action, result)
+	}
+	return len(blocks)
+}
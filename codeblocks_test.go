@@ -0,0 +1,136 @@
+package main
+
+import "testing"
+
+func TestExtractCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []CodeBlock
+	}{
+		{
+			name:    "language and file name on the fence",
+			message: "Here it is:\n```go main.go\npackage main\n```\n",
+			want:    []CodeBlock{{Path: "main.go", Language: "go", Content: "package main\n"}},
+		},
+		{
+			name:    "file name only on the fence",
+			message: "```src/app.py\nprint(1)\n```",
+			want:    []CodeBlock{{Path: "src/app.py", Content: "print(1)\n"}},
+		},
+		{
+			name:    "heading above the fence",
+			message: "### src/auth.go\n\n```go\npackage src\n```",
+			want:    []CodeBlock{{Path: "src/auth.go", Language: "go", Content: "package src\n"}},
+		},
+		{
+			name:    "bold label above the fence",
+			message: "**File: web/index.html**\n```html\n<p>hi</p>\n```",
+			want:    []CodeBlock{{Path: "web/index.html", Language: "html", Content: "<p>hi</p>\n"}},
+		},
+		{
+			name:    "list item with backticks above the fence",
+			message: "1. `cmd/tool/main.go`:\n```go\npackage main\n```",
+			want:    []CodeBlock{{Path: "cmd/tool/main.go", Language: "go", Content: "package main\n"}},
+		},
+		{
+			name:    "filepath comment",
+			message: "```go\n// filepath: internal/db/db.go\npackage db\n```",
+			want:    []CodeBlock{{Path: "internal/db/db.go", Language: "go", Content: "package db\n"}},
+		},
+		{
+			name:    "hash comment",
+			message: "```python\n# file: tools/run.py\nimport os\n```",
+			want:    []CodeBlock{{Path: "tools/run.py", Language: "python", Content: "import os\n"}},
+		},
+		{
+			name:    "html comment",
+			message: "```html\n<!-- filepath: index.html -->\n<html></html>\n```",
+			want:    []CodeBlock{{Path: "index.html", Language: "html", Content: "<html></html>\n"}},
+		},
+		{
+			name:    "language only uses the default name",
+			message: "Try this:\n\n```go\npackage main\n\nfunc main() {}\n```",
+			want:    []CodeBlock{{Path: "main.go", Language: "go", Content: "package main\n\nfunc main() {}\n", Default: true}},
+		},
+		{
+			name:    "sentence above is not a path",
+			message: "Update main.go like this:\n```rust\nfn main() {}\n```",
+			want:    []CodeBlock{{Path: "main.rs", Language: "rust", Content: "fn main() {}\n", Default: true}},
+		},
+		{
+			name:    "shell, output and unlabelled blocks are skipped",
+			message: "Run:\n```bash\ngo test ./...\n```\n```\nok\n```\n```json\n{}\n```",
+		},
+		{
+			name:    "fence naming something that is not a relative path is skipped",
+			message: "```go ../escape.go\npackage x\n```\n```go title=\"x\"\npackage y\n```",
+		},
+		{
+			name:    "unterminated block is skipped",
+			message: "```go main.go\npackage main\n",
+		},
+		{
+			name:    "later block for the same file wins",
+			message: "```go main.go\nv1\n```\ntext\n```go main.go\nv2\n```",
+			want:    []CodeBlock{{Path: "main.go", Language: "go", Content: "v2\n"}},
+		},
+		{
+			name:    "several files",
+			message: "### a.go\n```go\npackage a\n```\n### b.go\n```go\npackage b\n```",
+			want: []CodeBlock{
+				{Path: "a.go", Language: "go", Content: "package a\n"},
+				{Path: "b.go", Language: "go", Content: "package b\n"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractCodeBlocks(tt.message)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d blocks %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("block %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractAndWriteCodeBlocks(t *testing.T) {
+	newTestProject(t)
+	session := newSession("ask", "test-model")
+
+	writeTestFile(t, "src/.keep", "")
+
+	message := "### src/util.go\n```go\npackage src\n```"
+	if n := extractAndWriteCodeBlocks(message, session); n != 1 {
+		t.Fatalf("wrote %d blocks, want 1", n)
+	}
+	if got := readTestFile(t, "src/util.go"); got != "package src\n" {
+		t.Errorf("src/util.go = %q", got)
+	}
+	if len(session.FilesWritten) != 1 || session.FilesWritten[0] != "src/util.go" {
+		t.Errorf("session files = %v, want [src/util.go]", session.FilesWritten)
+	}
+}
+
+func TestExtractAndWriteCodeBlocksKeepsExistingDefault(t *testing.T) {
+	newTestProject(t)
+	session := newSession("ask", "test-model")
+
+	writeTestFile(t, "main.go", "package main // mine\n")
+
+	message := "```go\npackage main\n```\n### util.go\n```go\npackage main // util\n```"
+	if n := extractAndWriteCodeBlocks(message, session); n != 1 {
+		t.Fatalf("wrote %d blocks, want 1", n)
+	}
+	if got := readTestFile(t, "main.go"); got != "package main // mine\n" {
+		t.Errorf("main.go was overwritten: %q", got)
+	}
+	if got := readTestFile(t, "util.go"); got != "package main // util\n" {
+		t.Errorf("util.go = %q", got)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─── RESEARCH COMPARE ────────────────────────────────────────────────────────
+// 'keke research compare <model1> <model2> --dataset data.csv' trains and
+// evaluates both models on the same data and split, prints their metrics
+// side by side and asks the AI to interpret the difference. Each comparison
+// is saved to .keke/comparisons/<timestamp>.json
+
+const defaultTrainSplit = 0.8
+
+// ModelEvaluation - the outcome of training and evaluating one model
+type ModelEvaluation struct {
+	Model    string             `json:"model"`
+	Metrics  map[string]float64 `json:"metrics"` // by name, e.g. Accuracy, F1
+	Training string             `json:"training"`
+	Result   string             `json:"evaluation"`
+}
+
+// ModelComparison - a saved 'keke research compare' run
+type ModelComparison struct {
+	Time           time.Time       `json:"time"`
+	Dataset        string          `json:"dataset"`
+	Split          float64         `json:"split"` // share of rows used for training
+	ModelA         ModelEvaluation `json:"model_a"`
+	ModelB         ModelEvaluation `json:"model_b"`
+	Interpretation string          `json:"interpretation,omitempty"`
+	CreditsUsed    int             `json:"credits_used"`
+}
+
+func handleResearchCompare(args []string) {
+	datasetPath := ""
+	split := defaultTrainSplit
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--dataset" && i+1 < len(args):
+			datasetPath = args[i+1]
+			i++
+		case args[i] == "--split" && i+1 < len(args):
+			value, err := strconv.ParseFloat(args[i+1], 64)
+			if err != nil || value <= 0 || value >= 1 {
+				logError(fmt.Sprintf("Invalid --split: %s (use the training share, e.g. 0.8)", args[i+1]))
+				return
+			}
+			split = value
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+
+	var opts *askOptions
+	var models []string
+	if len(rest) > 0 {
+		var err error
+		if opts, err = parseAskFlags(rest); err != nil {
+			logError(err.Error())
+			return
+		}
+		models = strings.Fields(opts.Prompt)
+	}
+	if len(models) != 2 || datasetPath == "" {
+		logError("Usage: keke research compare <model1> <model2> --dataset <file> [--split 0.8]")
+		logInfo("Example: keke research compare random_forest xgboost --dataset churn.csv")
+		return
+	}
+	if _, err := os.Stat(datasetPath); err != nil {
+		logError(fmt.Sprintf("Cannot read dataset: %v", err))
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	comparison := &ModelComparison{Time: time.Now(), Dataset: datasetPath, Split: split}
+	params := map[string]interface{}{"dataset": datasetPath, "split": split}
+	comparison.ModelA, err = trainAndEvaluate(models[0], params)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	comparison.ModelB, err = trainAndEvaluate(models[1], params)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	if !jsonMode {
+		displayComparison(comparison)
+	}
+
+	logInfo(fmt.Sprintf("AI interpreting the results (%s)...", modelLabel(opts.Model)))
+	results, _ := json.MarshalIndent(comparison, "", "  ")
+	payload := map[string]interface{}{"model": opts.Model, "mode": "research"}
+	payload["conversation"] = []map[string]string{{
+		"role": "user",
+		"content": fmt.Sprintf("Two models were trained and evaluated on %s with a %.0f/%.0f train/test split. "+
+			"Write a brief interpretation: which model is better for this data, how large and meaningful the "+
+			"differences are, and what to try next. Do not run any actions.\n\n%s", datasetPath, split*100, (1-split)*100, results),
+	}}
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		logRequestError("AI error", err)
+	} else {
+		comparison.Interpretation = response.Message
+		comparison.CreditsUsed = response.CreditsUsed
+		if !jsonMode {
+			printMessage(response.Message)
+		}
+	}
+
+	path, err := saveComparison(comparison)
+	if err != nil {
+		logWarning(fmt.Sprintf("Failed to save comparison: %v", err))
+	}
+
+	if jsonMode {
+		emitJSON(comparison)
+		return
+	}
+	printDivider()
+	if path != "" {
+		logInfo(fmt.Sprintf("Saved to %s", path))
+	}
+	logInfo(fmt.Sprintf("Credits used: %d", comparison.CreditsUsed))
+}
+
+// trainAndEvaluate runs the train_model and evaluate_model actions for one
+// model, with params carrying the dataset and split
+func trainAndEvaluate(model string, params map[string]interface{}) (ModelEvaluation, error) {
+	evaluation := ModelEvaluation{Model: model}
+
+	evaluation.Training = handleTrainModel(Action{Type: "train_model", ModelType: model, Parameters: params})
+	if actionFailed(evaluation.Training) {
+		return evaluation, fmt.Errorf("Training %s did not complete: %s", model, evaluation.Training)
+	}
+	evaluation.Result = handleEvaluateModel(Action{Type: "evaluate_model", ModelType: model, Path: model, Parameters: params})
+	if actionFailed(evaluation.Result) {
+		return evaluation, fmt.Errorf("Evaluating %s did not complete: %s", model, evaluation.Result)
+	}
+
+	evaluation.Metrics = parseMetrics(evaluation.Training + "\n" + evaluation.Result)
+	return evaluation, nil
+}
+
+var metricPattern = regexp.MustCompile(`([A-Za-z][\w-]*): (-?[0-9]*\.?[0-9]+)`)
+
+// parseMetrics collects "Name: 0.91" pairs from action results
+func parseMetrics(text string) map[string]float64 {
+	metrics := map[string]float64{}
+	for _, m := range metricPattern.FindAllStringSubmatch(text, -1) {
+		if value, err := strconv.ParseFloat(m[2], 64); err == nil {
+			metrics[m[1]] = value
+		}
+	}
+	return metrics
+}
+
+// lowerIsBetter names the metrics where the smaller value wins
+var lowerIsBetter = []string{"loss", "mae", "mse", "rmse", "error"}
+
+// displayComparison prints the metrics of both models side by side, the
+// better value of each in green
+func displayComparison(c *ModelComparison) {
+	var names []string
+	for name := range c.ModelA.Metrics {
+		names = append(names, name)
+	}
+	for name := range c.ModelB.Metrics {
+		if _, ok := c.ModelA.Metrics[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	fmt.Printf("%s%s%s vs %s%s %s(%s, %.0f/%.0f split)%s\n", bold, magenta, c.ModelA.Model, c.ModelB.Model, reset,
+		dim, c.Dataset, c.Split*100, (1-c.Split)*100, reset)
+	printDivider()
+	fmt.Printf("%s%-14s %-16s %-16s%s\n", bold, "METRIC", c.ModelA.Model, c.ModelB.Model, reset)
+	for _, name := range names {
+		a, okA := c.ModelA.Metrics[name]
+		b, okB := c.ModelB.Metrics[name]
+		colorA, colorB := "", ""
+		if okA && okB && a != b {
+			if (a > b) != containsString(lowerIsBetter, strings.ToLower(name)) {
+				colorA = green
+			} else {
+				colorB = green
+			}
+		}
+		fmt.Printf("%-14s %s%-16s%s %s%-16s%s\n", name, colorA, metricCell(a, okA), reset, colorB, metricCell(b, okB), reset)
+	}
+	printDivider()
+}
+
+func metricCell(value float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// saveComparison writes c to .keke/comparisons/<timestamp>.json
+func saveComparison(c *ModelComparison) (string, error) {
+	if dryRun {
+		return "", nil
+	}
+	if err := os.MkdirAll(projectComparisonsDir(), 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(projectComparisonsDir(), c.Time.UTC().Format("20060102-150405")+".json")
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
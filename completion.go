@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─── SHELL COMPLETION ────────────────────────────────────────────────────────
+// 'keke completion bash|zsh|fish' prints a completion script to source from
+// the shell's startup file. Commands and flags come from the table below,
+// which follows the command switch in main.go. File arguments of rollback,
+// diff and snapshots complete against the files that have snapshots, listed
+// at completion time by the hidden 'keke __complete snapshots'
+
+type completionCommand struct {
+	Name        string
+	Subcommands []string
+	Flags       []string
+	Snapshots   bool // complete snapshotted file names
+}
+
+var modelFlags = []string{"--fast", "--smart", "--deep"}
+
+var completionCommands = []completionCommand{
+	{Name: "version"},
+	{Name: "init"},
+	{Name: "scaffold"},
+	{Name: "signup", Flags: []string{"--port"}},
+	{Name: "login", Flags: []string{"--port"}},
+	{Name: "logout"},
+	{Name: "whoami", Flags: []string{"--fresh"}},
+	{Name: "credits", Subcommands: []string{"budget"}},
+	{Name: "status"},
+	{Name: "session", Subcommands: []string{"clear"}},
+	{Name: "sessions", Subcommands: []string{"list"}},
+	{Name: "export", Flags: []string{"--session", "--format"}},
+	{Name: "plan", Flags: append([]string{"--file", "--max-steps", "--budget", "--session"}, modelFlags...)},
+	{Name: "migrate", Flags: append([]string{"--from", "--to"}, modelFlags...)},
+	{Name: "docs", Flags: append([]string{"--format"}, modelFlags...)},
+	{Name: "explain", Flags: append([]string{"--lines"}, modelFlags...)},
+	{Name: "search", Flags: modelFlags},
+	{Name: "test", Flags: append([]string{"--command", "--attempts"}, modelFlags...)},
+	{Name: "review", Flags: modelFlags},
+	{Name: "ask", Flags: append([]string{"--interactive", "--file", "--continue", "--session", "--use-plan", "--no-diff", "--no-git", "--no-project",
+		"--timeout", "--max-steps", "--budget"}, modelFlags...)},
+	{Name: "repl", Flags: append([]string{"--continue", "--session", "--no-diff", "--max-steps"}, modelFlags...)},
+	{Name: "research", Subcommands: []string{"compare"}, Flags: append([]string{"--output", "--dataset", "--split", "--file", "--continue", "--session", "--max-steps", "--budget"}, modelFlags...)},
+	{Name: "signal", Subcommands: []string{"watch", "history", "backtest"}, Flags: []string{"--timeframe", "--full", "--multi",
+		"--filter", "--alert", "--alert-threshold", "--limit", "--mark-outcome", "--clear"}},
+	{Name: "rollback", Flags: []string{"--all", "--at", "--before", "--latest", "--list", "--filter", "--preview"}, Snapshots: true},
+	{Name: "diff", Flags: []string{"--stat"}, Snapshots: true},
+	{Name: "snapshots", Subcommands: []string{"prune"}, Flags: []string{"--diff", "--keep", "--older-than"}, Snapshots: true},
+	{Name: "snapshot", Subcommands: []string{"save", "restore", "list", "delete"}},
+	{Name: "context", Subcommands: []string{"view", "set", "delete", "reset"}},
+	{Name: "clean", Flags: []string{"--older-than", "--compress"}},
+	{Name: "permissions", Subcommands: []string{"list", "reset", "revoke"}},
+	{Name: "audit", Flags: []string{"--since", "--action"}},
+	{Name: "changelog", Flags: []string{"--since"}},
+	{Name: "sandbox", Subcommands: []string{"test"}},
+	{Name: "upgrade", Flags: []string{"--check", "--version", "--rollback", "--channel"}},
+	{Name: "config", Subcommands: []string{"list", "get", "set", "validate"}},
+	{Name: "completion", Subcommands: []string{"bash", "zsh", "fish"}},
+	{Name: "help"},
+}
+
+var globalCompletionFlags = []string{"--dry-run", "--json", "--no-color", "--no-stream", "--provider", "--proxy", "--http-timeout", "--sandbox", "--sandbox-required", "--yes", "--force"}
+
+// Fixed values offered after a flag
+var completionFlagValues = map[string][]string{
+	"--timeframe": knownTimeframes,
+	"--filter":    {"BUY", "SELL", "HOLD"},
+	"--channel":   updateChannels,
+	"--format":    {"md", "html", "json"},
+	"--action":    auditActionList,
+	"--provider":  knownProviders,
+}
+
+func handleCompletion(args []string) {
+	if len(args) != 1 {
+		logError("Usage: keke completion bash|zsh|fish")
+		logInfo("Load it in your shell's startup file:")
+		logInfo("  bash (~/.bashrc):                  source <(keke completion bash)")
+		logInfo("  zsh  (~/.zshrc, after compinit):   source <(keke completion zsh)")
+		logInfo("  fish (~/.config/fish/config.fish): keke completion fish | source")
+		return
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion())
+	case "zsh":
+		fmt.Print(zshCompletion())
+	case "fish":
+		fmt.Print(fishCompletion())
+	default:
+		logError(fmt.Sprintf("Unsupported shell: %s (use bash, zsh or fish)", args[0]))
+	}
+}
+
+// handleCompleteHelper answers the scripts' queries: 'keke __complete
+// snapshots' prints the snapshotted file names, one per line
+func handleCompleteHelper(args []string) {
+	if len(args) != 1 || args[0] != "snapshots" || !isProjectInitialized() {
+		return
+	}
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return
+	}
+	var names []string
+	for name := range snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
+func completionCommandNames() string {
+	var names []string
+	for _, c := range completionCommands {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, " ")
+}
+
+// completionWords lists what may follow command c
+func completionWords(c completionCommand) string {
+	return strings.Join(append(append(append([]string{}, c.Subcommands...), c.Flags...), globalCompletionFlags...), " ")
+}
+
+func sortedFlagValues() []string {
+	var flags []string
+	for flag := range completionFlagValues {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+func bashCompletion() string {
+	var b strings.Builder
+	b.WriteString("# bash completion for keke. Load with: source <(keke completion bash)\n")
+	b.WriteString("_keke() {\n")
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\" words\n")
+	b.WriteString("    if [ \"$COMP_CWORD\" -eq 1 ]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", completionCommandNames())
+	b.WriteString("        return\n    fi\n")
+	b.WriteString("    case \"$prev\" in\n")
+	for _, flag := range sortedFlagValues() {
+		fmt.Fprintf(&b, "        %s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", flag, strings.Join(completionFlagValues[flag], " "))
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+	for _, c := range completionCommands {
+		words := completionWords(c)
+		if c.Snapshots {
+			words += " $(keke __complete snapshots 2>/dev/null)"
+		}
+		fmt.Fprintf(&b, "        %s) words=\"%s\" ;;\n", c.Name, words)
+	}
+	fmt.Fprintf(&b, "        *) words=\"%s\" ;;\n", strings.Join(globalCompletionFlags, " "))
+	b.WriteString("    esac\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$words\" -- \"$cur\"))\n")
+	b.WriteString("}\n")
+	b.WriteString("complete -o default -F _keke keke\n")
+	return b.String()
+}
+
+func zshCompletion() string {
+	var b strings.Builder
+	b.WriteString("#compdef keke\n")
+	b.WriteString("# zsh completion for keke. Load with: source <(keke completion zsh)\n")
+	b.WriteString("_keke() {\n")
+	b.WriteString("    if (( CURRENT == 2 )); then\n")
+	fmt.Fprintf(&b, "        compadd -- %s\n", completionCommandNames())
+	b.WriteString("        return\n    fi\n")
+	b.WriteString("    case ${words[CURRENT-1]} in\n")
+	for _, flag := range sortedFlagValues() {
+		fmt.Fprintf(&b, "        %s) compadd -- %s; return ;;\n", flag, strings.Join(completionFlagValues[flag], " "))
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    case ${words[2]} in\n")
+	for _, c := range completionCommands {
+		line := fmt.Sprintf("compadd -- %s", completionWords(c))
+		if c.Snapshots {
+			line += "; compadd -- ${(f)\"$(keke __complete snapshots 2>/dev/null)\"}"
+		}
+		fmt.Fprintf(&b, "        %s) %s ;;\n", c.Name, line)
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    _files\n")
+	b.WriteString("}\n")
+	b.WriteString("compdef _keke keke\n")
+	return b.String()
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for keke. Load with: keke completion fish | source\n")
+	b.WriteString("complete -c keke -e\n")
+	fmt.Fprintf(&b, "complete -c keke -n __fish_use_subcommand -f -a \"%s\"\n", completionCommandNames())
+	for _, flag := range globalCompletionFlags {
+		fmt.Fprintf(&b, "complete -c keke -l %s\n", strings.TrimPrefix(flag, "--"))
+	}
+	for _, c := range completionCommands {
+		when := fmt.Sprintf("-n \"__fish_seen_subcommand_from %s\"", c.Name)
+		if len(c.Subcommands) > 0 {
+			fmt.Fprintf(&b, "complete -c keke %s -f -a \"%s\"\n", when, strings.Join(c.Subcommands, " "))
+		}
+		for _, flag := range c.Flags {
+			if values, ok := completionFlagValues[flag]; ok {
+				fmt.Fprintf(&b, "complete -c keke %s -l %s -x -a \"%s\"\n", when, strings.TrimPrefix(flag, "--"), strings.Join(values, " "))
+				continue
+			}
+			fmt.Fprintf(&b, "complete -c keke %s -l %s\n", when, strings.TrimPrefix(flag, "--"))
+		}
+		if c.Snapshots {
+			fmt.Fprintf(&b, "complete -c keke %s -f -a \"(keke __complete snapshots 2>/dev/null)\"\n", when)
+		}
+	}
+	return b.String()
+}
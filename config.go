@@ -2,25 +2,41 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 	// "runtime"
 )
 
 // Version - injected at build time
 var Version = "v0.1.0"
 
-// API Configuration
+// API Configuration. Endpoints are paths under the API base URL, which the
+// api_base_url config key or KEKE_API_BASE_URL can point at a self-hosted
+// backend; resolve them with apiEndpoint
 const (
-	APIBaseURL = "https://ecpyqmpgqzitduidnfey.supabase.co/functions/v1"
-	
-	EndpointAuth    = APIBaseURL + "/auth-Function"
-	EndpointWhoami  = APIBaseURL + "/whoami"
-	EndpointCredits = APIBaseURL + "/credit-function"
-	EndpointAI      = APIBaseURL + "/swift-handler"      // Coding assistant
-	EndpointSignal  = APIBaseURL + "/swift-service"  // ✅ NEW: Forex trading signals
+	defaultAPIBaseURL = "https://ecpyqmpgqzitduidnfey.supabase.co/functions/v1"
+
+	EndpointAuth     = "/auth-Function"
+	EndpointRefresh  = EndpointAuth + "/refresh"
+	EndpointWhoami   = "/whoami"
+	EndpointCredits  = "/credit-function"
+	EndpointAI       = "/swift-handler"   // Coding assistant
+	EndpointSignal   = "/swift-service"   // Forex trading signals
+	EndpointAlert    = "/send-alert"      // Signal alert emails
+	EndpointBacktest = "/signal-backtest" // Signal model replayed on past prices
 )
 
+// apiEndpoint returns the full URL of endpoint on the configured backend
+func apiEndpoint(endpoint string) string {
+	return strings.TrimSuffix(getConfig().APIBaseURL, "/") + endpoint
+}
+
 // OAuth Configuration
 const (
 	CallbackPort = "8080"
@@ -37,6 +53,38 @@ func globalAuthFile() string {
 	return filepath.Join(globalDir(), "auth.json")
 }
 
+func globalConfigFile() string {
+	return filepath.Join(globalDir(), "config.json")
+}
+
+func globalSessionsDir() string {
+	return filepath.Join(globalDir(), "sessions")
+}
+
+func globalCreditsCacheFile() string {
+	return filepath.Join(globalDir(), "credits-cache.json")
+}
+
+func globalWhoamiCacheFile() string {
+	return filepath.Join(globalDir(), "whoami-cache.json")
+}
+
+func globalWatchlistFile() string {
+	return filepath.Join(globalDir(), "watchlist.json")
+}
+
+func globalSignalHistoryFile() string {
+	return filepath.Join(globalDir(), "signals.jsonl")
+}
+
+func globalAlertSettingsFile() string {
+	return filepath.Join(globalDir(), "alerts.json")
+}
+
+func globalPendingAlertsFile() string {
+	return filepath.Join(globalDir(), "pending-alerts.json")
+}
+
 // Project paths (.keke/)
 func projectDir() string {
 	cwd, _ := os.Getwd()
@@ -59,6 +107,22 @@ func projectContextFile() string {
 	return filepath.Join(projectDir(), "context.json")
 }
 
+func projectPlanFile() string {
+	return filepath.Join(projectDir(), "last-plan.json")
+}
+
+func projectComparisonsDir() string {
+	return filepath.Join(projectDir(), "comparisons")
+}
+
+func projectDangerPatternsFile() string {
+	return filepath.Join(projectDir(), "danger-patterns.json")
+}
+
+func projectAuditLogFile() string {
+	return filepath.Join(projectDir(), "audit.log")
+}
+
 // AuthData - token storage structure
 type AuthData struct {
 	AccessToken  string `json:"access_token"`
@@ -70,6 +134,15 @@ type AuthData struct {
 	ExpiresAt    int64  `json:"expires_at"`
 }
 
+// CreditInfo - credit balance as reported by the server
+type CreditInfo struct {
+	Remaining    int       `json:"remaining"`
+	MonthlyLimit int       `json:"monthly_limit"`
+	ResetDate    string    `json:"reset_date"`
+	Plan         string    `json:"plan"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
 // Read auth from ~/.keke/auth.json
 func readAuth() (*AuthData, error) {
 	data, err := os.ReadFile(globalAuthFile())
@@ -114,11 +187,41 @@ func isProjectInitialized() bool {
 // 	return runtime.GOARCH
 // }
 
-// Permissions structure
+// Permissions structure - allow-lists of paths/globs (commands for execute)
 type Permissions struct {
-	Read    bool `json:"read"`
-	Write   bool `json:"write"`
-	Execute bool `json:"execute"`
+	Read    []string `json:"read"`
+	Write   []string `json:"write"`
+	Execute []string `json:"execute"`
+}
+
+// grants returns the allow-list for permType
+func (p *Permissions) grants(permType string) []string {
+	switch permType {
+	case "read":
+		return p.Read
+	case "write":
+		return p.Write
+	case "execute":
+		return p.Execute
+	}
+	return nil
+}
+
+// grant adds pattern to the allow-list for permType
+func (p *Permissions) grant(permType, pattern string) {
+	for _, existing := range p.grants(permType) {
+		if existing == pattern {
+			return
+		}
+	}
+	switch permType {
+	case "read":
+		p.Read = append(p.Read, pattern)
+	case "write":
+		p.Write = append(p.Write, pattern)
+	case "execute":
+		p.Execute = append(p.Execute, pattern)
+	}
 }
 
 // Write permissions to project
@@ -128,4 +231,596 @@ func writePermissions(perms *Permissions) error {
 		return err
 	}
 	return os.WriteFile(projectPermissionsFile(), data, 0644)
+}
+
+// ─── USER CONFIG (~/.keke/config.json) ──────────────────────────────────────
+
+// Config - persistent user preferences, set with 'keke config set'
+type Config struct {
+	DefaultModel       string   `json:"default_model,omitempty"`
+	DefaultProvider    string   `json:"default_provider,omitempty"`
+	DefaultTimeframe   string   `json:"default_timeframe,omitempty"` // for keke signal
+	HTTPTimeoutSeconds int      `json:"http_timeout_seconds,omitempty"`
+	AITimeoutSeconds   int      `json:"ai_timeout_seconds,omitempty"`      // for the AI and signal endpoints
+	CommandTimeout     int      `json:"command_timeout_seconds,omitempty"` // for commands the AI runs
+	MaxIterations      int      `json:"max_iterations,omitempty"`
+	NoColor            bool     `json:"no_color,omitempty"`
+	AutoApproveRead    bool     `json:"auto_approve_read,omitempty"`
+	RetryMaxAttempts   int      `json:"retry_max_attempts,omitempty"`
+	MaxSnapshots       int      `json:"max_snapshots_per_file,omitempty"`
+	Sandbox            bool     `json:"sandbox,omitempty"`
+	SandboxImage       string   `json:"sandbox_image,omitempty"`
+	UpdateChannel      string   `json:"update_channel,omitempty"`
+	TemplatesRepo      string   `json:"templates_repo,omitempty"`
+	Budget             int      `json:"max_credits_per_session,omitempty"` // 0 = no limit
+	APIBaseURL         string   `json:"api_base_url,omitempty"`
+	NeverReadPatterns  []string `json:"never_read_patterns,omitempty"` // more secret file names, read only after confirmation
+}
+
+// AI providers the server can route to
+var knownProviders = []string{"anthropic", "groq", "openrouter", "openai"}
+
+// checkProvider accepts an empty name (the server's choice) or a known provider
+func checkProvider(name string) error {
+	if name == "" || containsString(knownProviders, name) {
+		return nil
+	}
+	return fmt.Errorf("unknown provider %s (use %s)", name, strings.Join(knownProviders, ", "))
+}
+
+// Supported keys, in display order
+var configKeys = []string{
+	"default_model",
+	"default_provider",
+	"default_timeframe",
+	"http_timeout_seconds",
+	"ai_timeout_seconds",
+	"command_timeout_seconds",
+	"max_iterations",
+	"no_color",
+	"auto_approve_read",
+	"retry_max_attempts",
+	"max_snapshots_per_file",
+	"sandbox",
+	"sandbox_image",
+	"update_channel",
+	"templates_repo",
+	"max_credits_per_session",
+	"api_base_url",
+	"never_read_patterns",
+}
+
+// Longest http_timeout_seconds accepted (exclusive); slower answers are
+// AI calls, which have ai_timeout_seconds
+const maxHTTPTimeoutSeconds = 300
+
+// Built-in defaults used when a key is not set
+func defaultConfig() *Config {
+	return &Config{
+		DefaultModel:       "smart",
+		DefaultTimeframe:   "4H",
+		HTTPTimeoutSeconds: 30,
+		AITimeoutSeconds:   120,
+		CommandTimeout:     60,
+		MaxIterations:      20,
+		RetryMaxAttempts:   4,
+		MaxSnapshots:       50,
+		SandboxImage:       "alpine:latest",
+		UpdateChannel:      "stable",
+		TemplatesRepo:      "Aimable2002/keke_templates",
+		APIBaseURL:         defaultAPIBaseURL,
+	}
+}
+
+// Read config from ~/.keke/config.json, filling in defaults
+func readConfig() (*Config, error) {
+	cfg := defaultConfig()
+	data, err := os.ReadFile(globalConfigFile())
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return defaultConfig(), fmt.Errorf("invalid %s: %v", globalConfigFile(), err)
+	}
+	return cfg, nil
+}
+
+// Write config to ~/.keke/config.json
+func writeConfig(cfg *Config) error {
+	if err := os.MkdirAll(globalDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(globalConfigFile(), data, 0644)
+}
+
+// configEnvVar names the environment variable that overrides key:
+// KEKE_ and the key in upper case, with a few shorter spellings
+func configEnvVar(key string) string {
+	switch key {
+	case "http_timeout_seconds":
+		return "KEKE_HTTP_TIMEOUT"
+	case "ai_timeout_seconds":
+		return "KEKE_AI_TIMEOUT"
+	case "command_timeout_seconds":
+		return "KEKE_CMD_TIMEOUT"
+	case "max_snapshots_per_file":
+		return "KEKE_MAX_SNAPSHOTS"
+	case "max_credits_per_session":
+		return "KEKE_BUDGET"
+	}
+	return "KEKE_" + strings.ToUpper(key)
+}
+
+// loadConfig reads ~/.keke/config.json and overlays any KEKE_* environment
+// variables, which take precedence. Invalid environment values are reported
+// and the file value kept
+func loadConfig() (*Config, error) {
+	cfg, err := readConfig()
+	for _, key := range configKeys {
+		value, ok := os.LookupEnv(configEnvVar(key))
+		if !ok {
+			continue
+		}
+		if setErr := cfg.set(key, value); setErr != nil {
+			logWarning(fmt.Sprintf("Ignoring %s: %v", configEnvVar(key), setErr))
+		}
+	}
+	return cfg, err
+}
+
+var loadedConfig *Config
+
+// getConfig returns the effective config (file, then environment), loaded
+// once per run. A broken config file is reported and defaults are used
+func getConfig() *Config {
+	if loadedConfig == nil {
+		cfg, err := loadConfig()
+		if err != nil {
+			logWarning(fmt.Sprintf("Ignoring config: %v", err))
+		}
+		loadedConfig = cfg
+	}
+	return loadedConfig
+}
+
+// saveConfigValue stores key in ~/.keke/config.json and applies it to this
+// run. Only the file layer is written, so environment overrides are never
+// persisted
+func saveConfigValue(key, value string) error {
+	cfg, err := readConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.set(key, value); err != nil {
+		return err
+	}
+	if err := writeConfig(cfg); err != nil {
+		return fmt.Errorf("Failed to save config: %v", err)
+	}
+	if _, ok := os.LookupEnv(configEnvVar(key)); ok {
+		logWarning(fmt.Sprintf("%s is set and overrides the saved %s", configEnvVar(key), key))
+		return nil
+	}
+	return getConfig().set(key, value)
+}
+
+// get returns the value of key formatted for display
+func (c *Config) get(key string) (string, error) {
+	switch key {
+	case "default_model":
+		return c.DefaultModel, nil
+	case "default_provider":
+		return c.DefaultProvider, nil
+	case "default_timeframe":
+		return c.DefaultTimeframe, nil
+	case "http_timeout_seconds":
+		return strconv.Itoa(c.HTTPTimeoutSeconds), nil
+	case "ai_timeout_seconds":
+		return strconv.Itoa(c.AITimeoutSeconds), nil
+	case "command_timeout_seconds":
+		return strconv.Itoa(c.CommandTimeout), nil
+	case "max_iterations":
+		return strconv.Itoa(c.MaxIterations), nil
+	case "no_color":
+		return strconv.FormatBool(c.NoColor), nil
+	case "auto_approve_read":
+		return strconv.FormatBool(c.AutoApproveRead), nil
+	case "retry_max_attempts":
+		return strconv.Itoa(c.RetryMaxAttempts), nil
+	case "max_snapshots_per_file":
+		return strconv.Itoa(c.MaxSnapshots), nil
+	case "sandbox":
+		return strconv.FormatBool(c.Sandbox), nil
+	case "sandbox_image":
+		return c.SandboxImage, nil
+	case "update_channel":
+		return c.UpdateChannel, nil
+	case "templates_repo":
+		return c.TemplatesRepo, nil
+	case "max_credits_per_session":
+		return strconv.Itoa(c.Budget), nil
+	case "api_base_url":
+		return c.APIBaseURL, nil
+	case "never_read_patterns":
+		return strings.Join(c.NeverReadPatterns, ","), nil
+	}
+	return "", unknownConfigKey(key)
+}
+
+// set parses and validates value before storing it under key
+func (c *Config) set(key, value string) error {
+	switch key {
+	case "default_model":
+		if value != "fast" && value != "smart" && value != "deep" {
+			return fmt.Errorf("default_model must be fast, smart or deep")
+		}
+		c.DefaultModel = value
+	case "default_provider":
+		if err := checkProvider(value); err != nil {
+			return err
+		}
+		c.DefaultProvider = value
+	case "default_timeframe":
+		tf, err := normalizeTimeframe(value)
+		if err != nil {
+			return fmt.Errorf("default_timeframe: %v", err)
+		}
+		c.DefaultTimeframe = tf
+	case "http_timeout_seconds":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n >= maxHTTPTimeoutSeconds {
+			return fmt.Errorf("http_timeout_seconds must be a number from 1 to %d", maxHTTPTimeoutSeconds-1)
+		}
+		c.HTTPTimeoutSeconds = n
+	case "ai_timeout_seconds":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("ai_timeout_seconds must be a positive number")
+		}
+		c.AITimeoutSeconds = n
+	case "command_timeout_seconds":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("command_timeout_seconds must be a positive number")
+		}
+		c.CommandTimeout = n
+	case "max_iterations":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("max_iterations must be a positive number")
+		}
+		c.MaxIterations = n
+	case "no_color":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("no_color must be true or false")
+		}
+		c.NoColor = b
+	case "auto_approve_read":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("auto_approve_read must be true or false")
+		}
+		c.AutoApproveRead = b
+	case "retry_max_attempts":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("retry_max_attempts must be a positive number")
+		}
+		c.RetryMaxAttempts = n
+	case "max_snapshots_per_file":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("max_snapshots_per_file must be a positive number")
+		}
+		c.MaxSnapshots = n
+	case "sandbox":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("sandbox must be true or false")
+		}
+		c.Sandbox = b
+	case "sandbox_image":
+		if value == "" {
+			return fmt.Errorf("sandbox_image must be a Docker image such as alpine:latest")
+		}
+		c.SandboxImage = value
+	case "update_channel":
+		if !validUpdateChannel(value) {
+			return fmt.Errorf("update_channel must be stable, beta or nightly")
+		}
+		c.UpdateChannel = value
+	case "templates_repo":
+		if owner, repo, ok := strings.Cut(value, "/"); !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+			return fmt.Errorf("templates_repo must be a GitHub repository such as owner/name")
+		}
+		c.TemplatesRepo = value
+	case "max_credits_per_session":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("max_credits_per_session must be a number of credits, 0 for no limit")
+		}
+		c.Budget = n
+	case "api_base_url":
+		if !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+			return fmt.Errorf("api_base_url must be an http:// or https:// URL")
+		}
+		c.APIBaseURL = strings.TrimSuffix(value, "/")
+	case "never_read_patterns":
+		var patterns []string
+		for _, pattern := range strings.Split(value, ",") {
+			pattern = strings.TrimSpace(pattern)
+			if pattern == "" {
+				continue
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern in never_read_patterns: %s", pattern)
+			}
+			patterns = append(patterns, pattern)
+		}
+		c.NeverReadPatterns = patterns
+	default:
+		return unknownConfigKey(key)
+	}
+	return nil
+}
+
+func unknownConfigKey(key string) error {
+	return fmt.Errorf("unknown config key '%s'. Valid keys: %s", key, strings.Join(configKeys, ", "))
+}
+
+// RetryConfig - how API requests are retried after transient failures
+type RetryConfig struct {
+	MaxAttempts     int
+	BaseDelay       time.Duration // doubled after every attempt
+	MaxDelay        time.Duration
+	RetryableStatus []int
+}
+
+// getRetryConfig returns the retry policy, honoring retry_max_attempts
+func getRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:     getConfig().RetryMaxAttempts,
+		BaseDelay:       time.Second,
+		MaxDelay:        32 * time.Second,
+		RetryableStatus: []int{429, 500, 502, 503, 504},
+	}
+}
+
+// ─── CONFIG COMMAND ──────────────────────────────────────────────────────────
+
+func handleConfig(args []string) {
+	if len(args) == 0 {
+		printConfigUsage()
+		return
+	}
+
+	switch args[0] {
+	case "list":
+		cfg := getConfig()
+		printDivider()
+		for _, key := range configKeys {
+			value, _ := cfg.get(key)
+			if value == "" {
+				value = dim + "(not set)" + reset
+			}
+			if envValue, ok := os.LookupEnv(configEnvVar(key)); ok && (&Config{}).set(key, envValue) == nil {
+				value += dim + " (from " + configEnvVar(key) + ")" + reset
+			}
+			logInfo(fmt.Sprintf("%-24s %s", key, value))
+		}
+		printDivider()
+		logInfo(fmt.Sprintf("Stored in %s; KEKE_* environment variables take precedence", globalConfigFile()))
+
+	case "get":
+		if len(args) < 2 {
+			logError("Usage: keke config get <key>")
+			return
+		}
+		value, err := getConfig().get(args[1])
+		if err != nil {
+			logError(err.Error())
+			return
+		}
+		fmt.Println(value)
+
+	case "validate":
+		handleConfigValidate()
+
+	case "set":
+		if len(args) < 3 {
+			logError("Usage: keke config set <key> <value>")
+			return
+		}
+		if err := saveConfigValue(args[1], args[2]); err != nil {
+			logError(err.Error())
+			return
+		}
+		logSuccess(fmt.Sprintf("%s = %s", args[1], args[2]))
+
+	default:
+		logError(fmt.Sprintf("Unknown subcommand: %s", args[0]))
+		printConfigUsage()
+	}
+}
+
+func printConfigUsage() {
+	logError("Usage: keke config <list|get|set|validate>")
+	logInfo("Examples:")
+	logInfo("  keke config list")
+	logInfo("  keke config validate")
+	logInfo("  keke config get default_model")
+	logInfo("  keke config set default_model deep")
+	logInfo(fmt.Sprintf("Keys: %s", strings.Join(configKeys, ", ")))
+	logInfo("Environment variables override the file: KEKE_DEFAULT_MODEL, KEKE_HTTP_TIMEOUT, KEKE_API_BASE_URL, ...")
+}
+
+// ─── CONFIG VALIDATION ───────────────────────────────────────────────────────
+// 'keke config validate' checks ~/.keke/config.json as written, without the
+// environment overlay: each value must pass the same checks as 'keke config
+// set'. Unknown keys are only warnings, so a config written by a newer keke
+// still loads. main() runs the check quietly at startup
+
+// Shorthand spellings people write by hand, and the key each stands for
+var deprecatedConfigKeys = map[string]string{
+	"model":        "default_model",
+	"provider":     "default_provider",
+	"timeframe":    "default_timeframe",
+	"timeout":      "http_timeout_seconds",
+	"budget":       "max_credits_per_session",
+	"max_steps":    "max_iterations",
+	"api_url":      "api_base_url",
+	"channel":      "update_channel",
+	"max_attempts": "retry_max_attempts",
+}
+
+// ConfigProblem - one finding of validateConfigFile
+type ConfigProblem struct {
+	Key     string `json:"key,omitempty"`
+	Error   bool   `json:"error"` // false for warnings
+	Message string `json:"message"`
+}
+
+// validateConfigFile checks the config file; a missing file has no problems
+func validateConfigFile() []ConfigProblem {
+	data, err := os.ReadFile(globalConfigFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []ConfigProblem{{Error: true, Message: err.Error()}}
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return []ConfigProblem{{Error: true, Message: fmt.Sprintf("not valid JSON: %v", err)}}
+	}
+
+	var keys []string
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []ConfigProblem
+	for _, key := range keys {
+		if !containsString(configKeys, key) {
+			message := "unknown key, ignored"
+			if replacement, ok := deprecatedConfigKeys[key]; ok {
+				message = fmt.Sprintf("deprecated, ignored: use %s", replacement)
+			}
+			problems = append(problems, ConfigProblem{Key: key, Message: message})
+			continue
+		}
+
+		// The JSON type must match the field, or the whole file is ignored
+		field, _ := json.Marshal(map[string]json.RawMessage{key: raw[key]})
+		err := json.Unmarshal(field, &Config{})
+		if err != nil {
+			err = fmt.Errorf("wrong type: %s", raw[key])
+		}
+		var value string
+		if err == nil {
+			value, err = configFileValue(raw[key])
+		}
+		if err == nil {
+			err = (&Config{}).set(key, value)
+		}
+		if err != nil {
+			problems = append(problems, ConfigProblem{Key: key, Error: true, Message: err.Error()})
+			continue
+		}
+		if key == "api_base_url" && insecureAPIBaseURL(value) {
+			problems = append(problems, ConfigProblem{Key: key, Message: "plain http:// sends your tokens unencrypted, use https:// unless the server is on this machine"})
+		}
+	}
+	return problems
+}
+
+// insecureAPIBaseURL reports whether value is a plain http:// URL to a host
+// other than this machine
+func insecureAPIBaseURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme != "http" {
+		return false
+	}
+	switch host := u.Hostname(); host {
+	case "localhost", "127.0.0.1", "::1":
+		return false
+	default:
+		return !strings.HasSuffix(host, ".localhost")
+	}
+}
+
+// configFileValue turns a JSON value into the text 'keke config set' takes:
+// strings as they are, numbers and booleans as written, lists joined by commas
+func configFileValue(raw json.RawMessage) (string, error) {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s, nil
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil {
+		return strings.Join(list, ","), nil
+	}
+	var scalar interface{}
+	json.Unmarshal(raw, &scalar)
+	switch scalar.(type) {
+	case float64, bool:
+		return string(raw), nil
+	}
+	return "", fmt.Errorf("unexpected value %s", raw)
+}
+
+func handleConfigValidate() {
+	problems := validateConfigFile()
+	errorCount := 0
+	for _, p := range problems {
+		if p.Error {
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		exitCode = exitError
+	}
+	if jsonMode {
+		emitJSON(problems)
+		return
+	}
+
+	for _, p := range problems {
+		message := p.Message
+		if p.Key != "" && !strings.HasPrefix(message, p.Key) {
+			message = p.Key + ": " + message
+		}
+		if p.Error {
+			logError(message)
+		} else {
+			logWarning(message)
+		}
+	}
+
+	switch {
+	case len(problems) == 0:
+		logSuccess(fmt.Sprintf("%s is valid", globalConfigFile()))
+	case errorCount > 0:
+		logInfo(fmt.Sprintf("%d errors, %d warnings in %s. Fix the values with 'keke config set'", errorCount, len(problems)-errorCount, globalConfigFile()))
+	default:
+		logInfo(fmt.Sprintf("%d warnings in %s", len(problems), globalConfigFile()))
+	}
+}
+
+// warnConfigProblems prints one line at startup when the config file has
+// problems, pointing at 'keke config validate'
+func warnConfigProblems() {
+	if problems := validateConfigFile(); len(problems) > 0 {
+		logWarning(fmt.Sprintf("%s has %d problems. Run 'keke config validate' for details", globalConfigFile(), len(problems)))
+	}
 }
\ No newline at end of file
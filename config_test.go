@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInsecureAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://api.example.com", false},
+		{"http://api.example.com", true},
+		{"http://10.0.0.5:8000/functions/v1", true},
+		{"http://localhost:54321/functions/v1", false},
+		{"http://127.0.0.1:8000", false},
+		{"http://[::1]:8000", false},
+		{"http://api.localhost", false},
+		{"http://localhost.example.com", true},
+	}
+	for _, tt := range tests {
+		if got := insecureAPIBaseURL(tt.url); got != tt.want {
+			t.Errorf("insecureAPIBaseURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestValidateConfigFileWarnsOnPlainHTTP(t *testing.T) {
+	newTestProject(t)
+	if err := os.MkdirAll(filepath.Dir(globalConfigFile()), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		url  string
+		want int
+	}{
+		{"http://api.example.com", 1},
+		{"http://localhost:54321", 0},
+		{"https://api.example.com", 0},
+	} {
+		config := `{"api_base_url": "` + tt.url + `"}`
+		if err := os.WriteFile(globalConfigFile(), []byte(config), 0600); err != nil {
+			t.Fatal(err)
+		}
+		problems := validateConfigFile()
+		if len(problems) != tt.want {
+			t.Errorf("%s: got problems %+v, want %d", tt.url, problems, tt.want)
+			continue
+		}
+		for _, p := range problems {
+			if p.Error || p.Key != "api_base_url" {
+				t.Errorf("%s: got %+v, want an api_base_url warning", tt.url, p)
+			}
+		}
+	}
+}
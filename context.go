@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ─── PROJECT CONTEXT ─────────────────────────────────────────────────────────
+// .keke/context.json holds facts the AI should remember about this project
+// ("database": "PostgreSQL"). It is sent with every AI request
+
+// readProjectContext returns the stored facts, or nil without a project
+func readProjectContext() (map[string]interface{}, error) {
+	data, err := os.ReadFile(projectContextFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	context := map[string]interface{}{}
+	if err := json.Unmarshal(data, &context); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", projectContextFile(), err)
+	}
+	return context, nil
+}
+
+func writeProjectContext(context map[string]interface{}) error {
+	data, err := json.MarshalIndent(context, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(projectContextFile(), append(data, '\n'), 0644)
+}
+
+func handleContext(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	if len(args) == 0 || args[0] == "view" {
+		viewContext()
+		return
+	}
+
+	switch args[0] {
+	case "reset":
+		if err := writeProjectContext(map[string]interface{}{}); err != nil {
+			logError(fmt.Sprintf("Failed to reset context: %v", err))
+			return
+		}
+		logSuccess("Context cleared")
+	case "set":
+		if len(args) < 3 {
+			logError("Usage: keke context set <key> <json-value>")
+			return
+		}
+		setContext(args[1], strings.Join(args[2:], " "))
+	case "delete":
+		if len(args) < 2 {
+			logError("Usage: keke context delete <key>")
+			return
+		}
+		deleteContext(args[1])
+	default:
+		logError(fmt.Sprintf("Unknown subcommand: %s", args[0]))
+		printContextUsage()
+	}
+}
+
+func printContextUsage() {
+	logInfo("Usage:")
+	logInfo("  keke context view")
+	logInfo("  keke context set <key> <json-value>")
+	logInfo("  keke context delete <key>")
+	logInfo("  keke context reset")
+}
+
+func viewContext() {
+	context, err := readProjectContext()
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	if jsonMode {
+		emitJSON(context)
+		return
+	}
+
+	if len(context) == 0 {
+		logInfo("Context is empty. Add a fact with: keke context set database '\"PostgreSQL\"'")
+		return
+	}
+
+	keys := make([]string, 0, len(context))
+	for key := range context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	printDivider()
+	for _, key := range keys {
+		value, _ := json.Marshal(context[key])
+		fmt.Printf("%s%s%s = %s\n", bold, key, reset, value)
+	}
+	printDivider()
+}
+
+// setContext stores value under key. Values that are not valid JSON are
+// stored as plain strings
+func setContext(key, value string) {
+	context, err := readProjectContext()
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	if context == nil {
+		context = map[string]interface{}{}
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		parsed = value
+	}
+	context[key] = parsed
+
+	if err := writeProjectContext(context); err != nil {
+		logError(fmt.Sprintf("Failed to save context: %v", err))
+		return
+	}
+	logSuccess(fmt.Sprintf("Set %s", key))
+}
+
+func deleteContext(key string) {
+	context, err := readProjectContext()
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	if _, ok := context[key]; !ok {
+		logError(fmt.Sprintf("No context key %s", key))
+		return
+	}
+	delete(context, key)
+
+	if err := writeProjectContext(context); err != nil {
+		logError(fmt.Sprintf("Failed to save context: %v", err))
+		return
+	}
+	logSuccess(fmt.Sprintf("Deleted %s", key))
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// ─── DANGEROUS COMMANDS ──────────────────────────────────────────────────────
+// Commands matching these patterns need a fresh confirmation every time they
+// run, even when a saved execute grant covers them. Projects can add their own
+// in .keke/danger-patterns.json:
+//   [{"pattern": "terraform destroy", "reason": "destroys infrastructure"}]
+
+// DangerPattern - a regular expression matched against the full command
+type DangerPattern struct {
+	Pattern string `json:"pattern"`
+	Reason  string `json:"reason"`
+}
+
+var defaultDangerPatterns = []DangerPattern{
+	{`\brm\s+(-\S+\s+)*-[a-zA-Z]*([rR][a-zA-Z]*f|f[a-zA-Z]*[rR])`, "recursive forced delete"},
+	{`\brm\s(.*\s)?(-[a-zA-Z]*[rR]|--recursive)\s(.*\s)?(-[a-zA-Z]*f|--force)(\s|$)`, "recursive forced delete"},
+	{`\brm\s(.*\s)?(-[a-zA-Z]*f|--force)\s(.*\s)?(-[a-zA-Z]*[rR]|--recursive)(\s|$)`, "recursive forced delete"},
+	{`\bdd\s+.*\bof=`, "raw disk write"},
+	{`\bmkfs(\.\w+)?\b`, "formats a filesystem"},
+	{`>\s*/dev/(sd|hd|nvme|disk)`, "writes to a block device"},
+	{`\bgit\s+push\b.*(\s--force\b|\s--force-with-lease\b|\s-f\b|\s\+\S+)`, "force push"},
+	{`\bgit\s+(reset\s+--hard|clean\s+-\S*f)`, "discards local changes"},
+	{`\b(curl|wget)\b[^|]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`, "pipes a download into a shell"},
+	{`\bchmod\s+(-\S+\s+)*777\b`, "world-writable permissions"},
+	{`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}`, "fork bomb"},
+	{`(^|[;&|]\s*|sudo\s+)(shutdown|reboot|halt|poweroff)(\s|$)`, "stops the machine"},
+}
+
+// loadDangerPatterns returns the built-in patterns plus the project's own.
+// Invalid custom patterns are reported and skipped
+func loadDangerPatterns() []DangerPattern {
+	patterns := defaultDangerPatterns
+
+	data, err := os.ReadFile(projectDangerPatternsFile())
+	if err != nil {
+		return patterns
+	}
+	var custom []DangerPattern
+	if err := json.Unmarshal(data, &custom); err != nil {
+		logWarning(fmt.Sprintf("Ignoring %s: %v", projectDangerPatternsFile(), err))
+		return patterns
+	}
+	for _, p := range custom {
+		if _, err := regexp.Compile(p.Pattern); err != nil {
+			logWarning(fmt.Sprintf("Ignoring danger pattern %q: %v", p.Pattern, err))
+			continue
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// dangerousCommand returns why command is high-risk, or "" when it is not
+func dangerousCommand(command string) string {
+	for _, p := range loadDangerPatterns() {
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil || !re.MatchString(command) {
+			continue
+		}
+		if p.Reason == "" {
+			return fmt.Sprintf("matches %q", p.Pattern)
+		}
+		return p.Reason
+	}
+	return ""
+}
+
+// confirmDangerousCommand asks for this one run only; nothing is saved. The
+// answer must be typed in full so a stray "y" can't approve it. --yes alone
+// refuses; only --yes --force runs it unasked
+func confirmDangerousCommand(command, reason string) bool {
+	fmt.Println()
+	logWarning(fmt.Sprintf("DANGEROUS COMMAND (%s)", reason))
+	fmt.Printf("  %s%s%s\n", red, command, reset)
+	fmt.Println()
+
+	if assumeYes {
+		if forceFlag {
+			logWarning("Running it because of --yes --force")
+			return true
+		}
+		logError("Dangerous command refused: --yes does not cover it, add --force to allow")
+		return false
+	}
+
+	if prompt("Type 'yes' to run it anyway") != "yes" {
+		logError("Dangerous command refused")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─── DATASETS ────────────────────────────────────────────────────────────────
+// Tabular data loaded for research actions: CSV/TSV with a header row, or
+// JSON holding an array of objects (at the top level or under one key) or
+// one object per line
+
+type Dataset struct {
+	Path    string
+	Format  string // CSV, TSV or JSON
+	Columns []string
+	Rows    [][]string
+}
+
+// loadDataset reads a dataset, telling JSON from CSV by the extension or,
+// failing that, the first character. TSV is read when the extension is .tsv
+func loadDataset(path string) (*Dataset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	trimmed := bytes.TrimSpace(data)
+	if ext == ".json" || ext == ".jsonl" || ext == ".ndjson" ||
+		(ext != ".csv" && ext != ".tsv" && len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')) {
+		return loadJSONDataset(path, trimmed)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1 // tolerate ragged rows
+	format := "CSV"
+	if ext == ".tsv" {
+		reader.Comma = '\t'
+		format = "TSV"
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s is empty", path)
+	}
+
+	header := make([]string, len(records[0]))
+	for i, name := range records[0] {
+		header[i] = strings.TrimSpace(name)
+	}
+	return &Dataset{Path: path, Format: format, Columns: header, Rows: records[1:]}, nil
+}
+
+// loadJSONDataset turns JSON records into rows, one column per key; nested
+// values are kept as JSON text
+func loadJSONDataset(path string, data []byte) (*Dataset, error) {
+	records, err := jsonRecords(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON dataset: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s has no records", path)
+	}
+
+	d := &Dataset{Path: path, Format: "JSON"}
+	index := map[string]int{}
+	for _, record := range records {
+		// Objects keep no key order, so new keys are added alphabetically
+		var keys []string
+		for key := range record {
+			if _, ok := index[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			index[key] = len(d.Columns)
+			d.Columns = append(d.Columns, key)
+		}
+	}
+
+	for _, record := range records {
+		row := make([]string, len(d.Columns))
+		for key, value := range record {
+			row[index[key]] = jsonCell(value)
+		}
+		d.Rows = append(d.Rows, row)
+	}
+	return d, nil
+}
+
+// jsonRecords accepts [{...}, ...], {"key": [{...}, ...]} or JSON Lines
+func jsonRecords(data []byte) ([]map[string]interface{}, error) {
+	var records []map[string]interface{}
+	if data[0] == '[' {
+		err := json.Unmarshal(data, &records)
+		return records, err
+	}
+
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal(data, &wrapper); err == nil {
+		keys := make([]string, 0, len(wrapper))
+		for key := range wrapper {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if json.Unmarshal(wrapper[key], &records) == nil && len(records) > 0 {
+				return records, nil
+			}
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	records = nil
+	for decoder.More() {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func jsonCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+func (d *Dataset) columnIndex(name string) int {
+	for i, column := range d.Columns {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// numericColumn returns the values of a column, skipping missing cells. It
+// fails when the column does not exist or holds non-numeric values
+func (d *Dataset) numericColumn(name string) ([]float64, error) {
+	index := d.columnIndex(name)
+	if index < 0 {
+		return nil, fmt.Errorf("column '%s' not found (columns: %s)", name, strings.Join(d.Columns, ", "))
+	}
+
+	var values []float64
+	for _, row := range d.Rows {
+		if index >= len(row) || isMissing(row[index]) {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(row[index]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("column '%s' is not numeric (value %q)", name, row[index])
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("column '%s' has no values", name)
+	}
+	return values, nil
+}
+
+// numericColumns lists the columns whose values all parse as numbers
+func (d *Dataset) numericColumns() []string {
+	var names []string
+	for _, name := range d.Columns {
+		if _, err := d.numericColumn(name); err == nil {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func isMissing(cell string) bool {
+	switch strings.ToLower(strings.TrimSpace(cell)) {
+	case "", "na", "nan", "null", "none":
+		return true
+	}
+	return false
+}
+
+// ─── DATASET SUMMARY ─────────────────────────────────────────────────────────
+
+// Datasets up to this size are sent whole along with their summary
+const maxInlineDatasetBytes = 1024 * 1024
+
+// datasetSampleRows is how many rows a summary shows
+const datasetSampleRows = 5
+
+// summarizeDataset describes a dataset for the AI: shape, each column's type
+// and missing values, ranges of numeric columns and the first rows
+func summarizeDataset(d *Dataset, size int64) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Dataset %s (%s, %s): %d rows x %d columns\n\n", d.Path, d.Format, formatBytes(size), len(d.Rows), len(d.Columns))
+
+	b.WriteString("Columns:\n")
+	for i, name := range d.Columns {
+		nulls := 0
+		for _, row := range d.Rows {
+			if i >= len(row) || isMissing(row[i]) {
+				nulls++
+			}
+		}
+		if values, err := d.numericColumn(name); err == nil {
+			s := describe(values)
+			fmt.Fprintf(&b, "  %s: numeric, %d nulls, min %g, mean %.4g, max %g\n", name, nulls, s.Min, s.Mean, s.Max)
+		} else {
+			fmt.Fprintf(&b, "  %s: text, %d nulls\n", name, nulls)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nFirst %d rows:\n", min(datasetSampleRows, len(d.Rows)))
+	b.WriteString("  " + strings.Join(d.Columns, ", ") + "\n")
+	for _, row := range d.Rows[:min(datasetSampleRows, len(d.Rows))] {
+		b.WriteString("  " + strings.Join(row, ", ") + "\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// ─── STATISTICS ──────────────────────────────────────────────────────────────
+
+type columnStats struct {
+	Count                  int
+	Mean, Std              float64
+	Min, Q1, Median, Q3    float64
+	Max                    float64
+	LowerFence, UpperFence float64 // 1.5 IQR beyond the quartiles
+	Outliers               int
+}
+
+func describe(values []float64) columnStats {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	s := columnStats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Q1:     quantile(sorted, 0.25),
+		Median: quantile(sorted, 0.5),
+		Q3:     quantile(sorted, 0.75),
+	}
+
+	sum := 0.0
+	for _, v := range sorted {
+		sum += v
+	}
+	s.Mean = sum / float64(s.Count)
+
+	// Sample standard deviation
+	if s.Count > 1 {
+		variance := 0.0
+		for _, v := range sorted {
+			variance += (v - s.Mean) * (v - s.Mean)
+		}
+		s.Std = math.Sqrt(variance / float64(s.Count-1))
+	}
+
+	iqr := s.Q3 - s.Q1
+	s.LowerFence = s.Q1 - 1.5*iqr
+	s.UpperFence = s.Q3 + 1.5*iqr
+	for _, v := range sorted {
+		if v < s.LowerFence || v > s.UpperFence {
+			s.Outliers++
+		}
+	}
+	return s
+}
+
+// quantile interpolates linearly between the closest ranks of sorted
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// pearson returns the correlation of two columns over the rows where both
+// have a numeric value
+func (d *Dataset) pearson(a, b string) (float64, int, error) {
+	ia, ib := d.columnIndex(a), d.columnIndex(b)
+	if ia < 0 || ib < 0 {
+		return 0, 0, fmt.Errorf("column '%s' or '%s' not found", a, b)
+	}
+
+	var xs, ys []float64
+	for _, row := range d.Rows {
+		if ia >= len(row) || ib >= len(row) || isMissing(row[ia]) || isMissing(row[ib]) {
+			continue
+		}
+		x, errX := strconv.ParseFloat(strings.TrimSpace(row[ia]), 64)
+		y, errY := strconv.ParseFloat(strings.TrimSpace(row[ib]), 64)
+		if errX != nil || errY != nil {
+			return 0, 0, fmt.Errorf("columns '%s' and '%s' must both be numeric", a, b)
+		}
+		xs = append(xs, x)
+		ys = append(ys, y)
+	}
+	if len(xs) < 2 {
+		return 0, len(xs), fmt.Errorf("not enough paired values in '%s' and '%s'", a, b)
+	}
+
+	meanX, meanY := 0.0, 0.0
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, len(xs), fmt.Errorf("'%s' or '%s' is constant", a, b)
+	}
+	return cov / math.Sqrt(varX*varY), len(xs), nil
+}
+
+// ─── ACTION PARAMETERS ───────────────────────────────────────────────────────
+
+// stringParam returns a string parameter sent by the AI
+func stringParam(params map[string]interface{}, key string) string {
+	if value, ok := params[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+// stringListParam accepts either a list of strings or a comma-separated
+// string
+func stringListParam(params map[string]interface{}, key string) []string {
+	switch value := params[key].(type) {
+	case []interface{}:
+		var list []string
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	case string:
+		var list []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	return nil
+}
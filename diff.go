@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ─── DIFF ────────────────────────────────────────────────────────────────────
+// Compare a file against one of its snapshots (CLI-only, no AI involved).
+// Without a file, 'keke diff' shows everything the last session changed
+
+func handleDiff(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	target, statOnly := "", false
+	for _, arg := range args {
+		switch {
+		case arg == "--stat":
+			statOnly = true
+		case target == "" && !strings.HasPrefix(arg, "-"):
+			target = arg
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", arg))
+			logInfo("Usage: keke diff [--stat] | keke diff <file>")
+			return
+		}
+	}
+
+	if target == "" {
+		diffSession(statOnly)
+		return
+	}
+
+	current, err := os.ReadFile(target)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", target, err))
+		return
+	}
+
+	snapshots, err := loadSnapshots()
+	snaps := snapshotsOf(snapshots, target)
+	if err != nil || len(snaps) == 0 {
+		logInfo(fmt.Sprintf("No snapshots found for: %s", target))
+		return
+	}
+
+	// Default to the most recent snapshot, let the user pick otherwise
+	snapshot := snaps[0]
+	if len(snaps) > 1 {
+		printDivider()
+		logInfo(fmt.Sprintf("Snapshots of %s:", target))
+		fmt.Println()
+		for i, snap := range snaps {
+			fmt.Printf("  %d. %s\n", i+1, snap.Timestamp)
+		}
+		printDivider()
+
+		response := prompt("Enter number to compare (Enter for most recent)")
+		if response != "" {
+			var index int
+			fmt.Sscanf(response, "%d", &index)
+			if index < 1 || index > len(snaps) {
+				logError("Invalid selection")
+				return
+			}
+			snapshot = snaps[index-1]
+		}
+	}
+
+	showSnapshotDiff(target, current, snapshot)
+}
+
+// diffSession shows, for each file the latest session wrote, the diff from
+// the snapshot taken before its first write to the file as it is now,
+// followed by a --stat style summary
+func diffSession(statOnly bool) {
+	session, err := latestProjectSession()
+	if err != nil {
+		logInfo("No session found for this project")
+		return
+	}
+	if len(session.FilesWritten) == 0 {
+		logInfo(fmt.Sprintf("Session %s did not write any files", session.ID))
+		return
+	}
+	snapshots, _ := loadSnapshots()
+	if jsonMode {
+		statOnly = true // only the summary is emitted
+	}
+
+	type fileStat struct {
+		path             string
+		added, removed   int
+		binary, notFound bool
+	}
+	var stats []fileStat
+	for _, path := range session.FilesWritten {
+		var old []byte
+		oldName := path + " (new file)"
+		if snap := sessionStartSnapshot(snapshots, session, path); snap != nil {
+			if old, err = readSnapshot(*snap); err != nil {
+				logWarning(fmt.Sprintf("%s: failed to read snapshot: %v", path, err))
+				continue
+			}
+			oldName = fmt.Sprintf("%s (%s)", path, snap.Timestamp)
+		}
+		current, err := os.ReadFile(path)
+		stat := fileStat{path: path, notFound: os.IsNotExist(err)}
+		if err != nil && !stat.notFound {
+			logWarning(fmt.Sprintf("Failed to read %s: %v", path, err))
+			continue
+		}
+
+		if isBinary(old) || isBinary(current) {
+			if !bytes.Equal(old, current) {
+				stat.binary = true
+				stats = append(stats, stat)
+			}
+			continue
+		}
+		for _, op := range diffLines(splitLines(string(old)), splitLines(string(current))) {
+			switch op.kind {
+			case '+':
+				stat.added++
+			case '-':
+				stat.removed++
+			}
+		}
+		if stat.added == 0 && stat.removed == 0 {
+			continue
+		}
+		stats = append(stats, stat)
+
+		if !statOnly {
+			newName := path + " (current)"
+			if stat.notFound {
+				newName = path + " (deleted)"
+			}
+			printColoredDiff(unifiedDiff(oldName, newName, old, current))
+			fmt.Println()
+		}
+	}
+
+	if jsonMode {
+		var out []map[string]interface{}
+		for _, st := range stats {
+			out = append(out, map[string]interface{}{"file": st.path, "added": st.added, "removed": st.removed, "binary": st.binary})
+		}
+		emitJSON(map[string]interface{}{"session": session.ID, "files": out})
+		return
+	}
+
+	if len(stats) == 0 {
+		logSuccess(fmt.Sprintf("No changes since session %s started", session.ID))
+		return
+	}
+
+	printDivider()
+	totalAdded, totalRemoved := 0, 0
+	for _, st := range stats {
+		if st.binary {
+			fmt.Printf(" %-40s binary file changed\n", st.path)
+			continue
+		}
+		fmt.Printf(" %-40s %s+%d%s %s-%d%s\n", st.path, green, st.added, reset, red, st.removed, reset)
+		totalAdded += st.added
+		totalRemoved += st.removed
+	}
+	printDivider()
+	logInfo(fmt.Sprintf("%d files changed, %d insertions(+), %d deletions(-) in session %s", len(stats), totalAdded, totalRemoved, session.ID))
+	logInfo("Undo them with: keke rollback --all")
+}
+
+// ─── DIFF HELPERS ────────────────────────────────────────────────────────────
+
+// showSnapshotDiff prints the diff from snapshot to the current content of
+// target
+func showSnapshotDiff(target string, current []byte, snapshot SnapshotInfo) {
+	old, err := readSnapshot(snapshot)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read snapshot: %v", err))
+		return
+	}
+
+	if isBinary(old) || isBinary(current) {
+		logWarning(fmt.Sprintf("%s is a binary file, cannot show diff", target))
+		return
+	}
+
+	diff := unifiedDiff(
+		fmt.Sprintf("%s (%s)", target, snapshot.Timestamp),
+		fmt.Sprintf("%s (current)", target),
+		old,
+		current,
+	)
+
+	if diff == "" {
+		logSuccess("No changes since snapshot")
+		return
+	}
+
+	printColoredDiff(diff)
+}
+
+// previewWrite shows what writing content to path would change and asks the
+// user to confirm
+func previewWrite(path, content string) bool {
+	fmt.Println()
+	old, err := os.ReadFile(path)
+	if err != nil {
+		logInfo(fmt.Sprintf("%s (new file, %d lines)", path, len(splitLines(content))))
+	} else if isBinary(old) || isBinary([]byte(content)) {
+		logInfo(fmt.Sprintf("%s (binary file, %d bytes)", path, len(content)))
+	} else {
+		diff := unifiedDiff(path+" (current)", path+" (proposed)", old, []byte(content))
+		if diff == "" {
+			logInfo(fmt.Sprintf("%s (no changes)", path))
+			return true
+		}
+		printColoredDiff(diff)
+	}
+
+	return promptYesNo("Apply this change? (y/n)")
+}
+
+// isBinary reports whether data looks like binary content (NUL in first 8KB)
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) != -1
+}
+
+// printColoredDiff prints a unified diff with additions in green and
+// removals in red
+func printColoredDiff(diff string) {
+	fmt.Print(colorDiff(diff))
+}
+
+// colorDiff returns a unified diff with additions in green and removals in red
+func colorDiff(diff string) string {
+	var out strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			out.WriteString(bold + line + reset + "\n")
+		case strings.HasPrefix(line, "@@"):
+			out.WriteString(cyan + line + reset + "\n")
+		case strings.HasPrefix(line, "+"):
+			out.WriteString(green + line + reset + "\n")
+		case strings.HasPrefix(line, "-"):
+			out.WriteString(red + line + reset + "\n")
+		default:
+			out.WriteString(line + "\n")
+		}
+	}
+	return out.String()
+}
+
+// diffFiles returns the colored diff that restoring snapshot over original
+// would apply, or "No changes" when they are identical
+func diffFiles(original, snapshot []byte) string {
+	diff := unifiedDiff("current", "snapshot", original, snapshot)
+	if diff == "" {
+		return "No changes\n"
+	}
+	return colorDiff(diff)
+}
+
+// showPaged prints text through $PAGER when stdout is a terminal, and
+// directly otherwise or when the pager fails to start
+func showPaged(text string) {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 || !isTerminal(os.Stdout) {
+		fmt.Print(text)
+		return
+	}
+
+	cmd := exec.Command(pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Print(text)
+	}
+}
+
+// unifiedDiff returns a unified diff (3 lines of context) between old and
+// new, or "" when they are identical
+func unifiedDiff(oldName, newName string, old, new []byte) string {
+	a := splitLines(string(old))
+	b := splitLines(string(new))
+	ops := diffLines(a, b)
+
+	const context = 3
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	changed := false
+	i := 0
+	for i < len(ops) {
+		// Find next change
+		for i < len(ops) && ops[i].kind == ' ' {
+			i++
+		}
+		if i == len(ops) {
+			break
+		}
+		changed = true
+
+		// Extend hunk until a run of more than 2*context unchanged lines
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				end += context
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = run
+		}
+
+		// Line numbers at hunk start
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:start] {
+			if op.kind != '+' {
+				oldLine++
+			}
+			if op.kind != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&out, "%c%s\n", op.kind, op.text)
+		}
+
+		i = end
+	}
+
+	if !changed {
+		return ""
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+type diffOp struct {
+	kind byte // ' ' unchanged, '-' removed, '+' added
+	text string
+}
+
+// diffLines computes a shortest edit script between a and b (Myers)
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] holds v[-d-1 .. d+1] as it was at the start of round d
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		window := make([]int, 2*d+3)
+		copy(window, v[offset-d-1:offset+d+2])
+		trace = append(trace, window)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrackDiff(trace, a, b)
+			}
+		}
+	}
+
+	return nil
+}
+
+func backtrackDiff(trace [][]int, a, b []string) []diffOp {
+	var ops []diffOp
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, diffOp{'+', b[y-1]})
+			} else {
+				ops = append(ops, diffOp{'-', a[x-1]})
+			}
+		}
+
+		x, y = prevX, prevY
+	}
+
+	// Reverse into forward order
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
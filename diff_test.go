@@ -0,0 +1,67 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestDiffFiles(t *testing.T) {
+	tests := []struct {
+		name               string
+		original, snapshot string
+		want               string
+	}{
+		{
+			name:     "added lines only",
+			original: "a\nb\n",
+			snapshot: "a\nb\nc\nd\n",
+			want: "--- current\n+++ snapshot\n@@ -1,2 +1,4 @@\n" +
+				" a\n b\n+c\n+d\n",
+		},
+		{
+			name:     "removed lines only",
+			original: "a\nb\nc\n",
+			snapshot: "a\n",
+			want: "--- current\n+++ snapshot\n@@ -1,3 +1,1 @@\n" +
+				" a\n-b\n-c\n",
+		},
+		{
+			name:     "mixed edits",
+			original: "package main\n\nfunc old() {}\n",
+			snapshot: "package main\n\nfunc new() {}\nfunc extra() {}\n",
+			want: "--- current\n+++ snapshot\n@@ -1,3 +1,4 @@\n" +
+				" package main\n \n-func old() {}\n+func new() {}\n+func extra() {}\n",
+		},
+		{
+			name:     "identical files",
+			original: "same\n",
+			snapshot: "same\n",
+			want:     "No changes\n",
+		},
+		{
+			name: "both empty",
+			want: "No changes\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansiPattern.ReplaceAllString(diffFiles([]byte(tt.original), []byte(tt.snapshot)), "")
+			if got != tt.want {
+				t.Errorf("diffFiles() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffFilesColors(t *testing.T) {
+	diff := diffFiles([]byte("old\n"), []byte("new\n"))
+	if !strings.Contains(diff, red+"-old"+reset) {
+		t.Errorf("removed line not red:\n%q", diff)
+	}
+	if !strings.Contains(diff, green+"+new"+reset) {
+		t.Errorf("added line not green:\n%q", diff)
+	}
+}
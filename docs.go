@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ─── DOCS ────────────────────────────────────────────────────────────────────
+// 'keke docs' has the AI add or update doc comments in the project's source
+// files and write a DOCS file summarising the public API. Every change is a
+// write_file action, so permissions, diffs, snapshots and --dry-run apply
+
+// Source files the AI documents, with the comment style it should use
+var docStyles = map[string]string{
+	".go":   "godoc comments: a // comment directly above each exported identifier, starting with its name",
+	".py":   "NumPy-style docstrings (Parameters, Returns, Raises sections)",
+	".js":   "JSDoc comments",
+	".jsx":  "JSDoc comments",
+	".ts":   "TSDoc comments",
+	".tsx":  "TSDoc comments",
+	".rs":   "rustdoc /// comments",
+	".java": "Javadoc comments",
+	".rb":   "YARD comments",
+	".c":    "Doxygen comments",
+	".h":    "Doxygen comments",
+	".cpp":  "Doxygen comments",
+}
+
+// At most this many files are handed to the AI in one run
+const maxDocsFiles = 200
+
+var docsFiles = map[string]string{
+	"md":   "DOCS.md",
+	"html": "DOCS.html",
+	"json": "DOCS.json",
+}
+
+func handleDocs(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	format := "md"
+	model := getConfig().DefaultModel
+	var paths []string
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			if i+1 >= len(args) {
+				logError("--format needs md, html or json")
+				return
+			}
+			format = strings.ToLower(args[i+1])
+			i++
+		case "--fast", "--smart", "--deep":
+			model = strings.TrimPrefix(args[i], "--")
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				logError(fmt.Sprintf("Unknown flag: %s", args[i]))
+				logInfo("Usage: keke docs [path...] [--format md|html|json] [--dry-run]")
+				return
+			}
+			paths = append(paths, args[i])
+		}
+	}
+
+	output, ok := docsFiles[format]
+	if !ok {
+		logError(fmt.Sprintf("Invalid --format %s (use md, html or json)", format))
+		return
+	}
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := collectDocsFiles(paths)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to list files: %v", err))
+		return
+	}
+	if len(files) == 0 {
+		logInfo("No source files to document")
+		return
+	}
+	if len(files) > maxDocsFiles {
+		logWarning(fmt.Sprintf("%d source files found, documenting the first %d", len(files), maxDocsFiles))
+		files = files[:maxDocsFiles]
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("AI documenting %d files, summary in %s...", len(files), output))
+
+	session := newSession("docs", model)
+	conversationLoop(session, docsPrompt(files, format, output), model, auth)
+}
+
+// collectDocsFiles lists the source files under paths, skipping ignored ones
+func collectDocsFiles(paths []string) ([]string, error) {
+	ignore := loadIgnorePatterns()
+	seen := make(map[string]bool)
+	var files []string
+
+	for _, root := range paths {
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if isIgnored(path, info.IsDir(), ignore) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() || seen[path] {
+				return nil
+			}
+			if _, ok := docStyles[strings.ToLower(filepath.Ext(path))]; ok && !strings.HasSuffix(path, "_test.go") {
+				seen[path] = true
+				files = append(files, filepath.ToSlash(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// docsPrompt tells the AI which files to document, in which style, and to
+// deliver every change as a write_file action
+func docsPrompt(files []string, format, output string) string {
+	styles := make(map[string]bool)
+	for _, file := range files {
+		styles[filepath.Ext(file)] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("Document this project. Read each file below, then add or update doc comments in place and write each changed file back with a write_file action containing the complete file. Change comments only, never code.\n\n")
+
+	b.WriteString("Comment style:\n")
+	var exts []string
+	for ext := range styles {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		fmt.Fprintf(&b, "- %s files: %s\n", ext, docStyles[ext])
+	}
+
+	fmt.Fprintf(&b, "\nFinally write %s summarising the public API (packages or modules, exported types and functions, one line each)", output)
+	switch format {
+	case "html":
+		b.WriteString(" as a standalone HTML page.\n")
+	case "json":
+		b.WriteString(` as JSON: {"modules": [{"path": "...", "summary": "...", "symbols": [{"name": "...", "kind": "...", "doc": "..."}]}]}.` + "\n")
+	default:
+		b.WriteString(" in Markdown.\n")
+	}
+
+	b.WriteString("\nFiles:\n")
+	for _, file := range files {
+		fmt.Fprintf(&b, "- %s\n", file)
+	}
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ─── EXPLAIN ─────────────────────────────────────────────────────────────────
+// 'keke explain <file> [--lines 10-50]' asks the AI to describe what a file
+// (or part of it) does
+
+// Explanation - structured answer returned in explain mode
+type Explanation struct {
+	Purpose      string          `json:"purpose"`
+	KeySymbols   []ExplainSymbol `json:"key_symbols"`
+	Dependencies []string        `json:"dependencies"`
+	Patterns     []string        `json:"patterns"`
+}
+
+type ExplainSymbol struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // type, function, method, const, var
+	Description string `json:"description"`
+	Exported    bool   `json:"exported"`
+}
+
+// Sent with the code so the reply is a single Explanation JSON object
+const explainInstruction = `Explain this code to a developer new to the codebase. Reply with ONLY a JSON object, no prose and no code fences, in this shape:
+{"purpose": "...", "key_symbols": [{"name": "...", "kind": "type|function|method|const|var", "description": "...", "exported": true}], "dependencies": ["..."], "patterns": ["..."]}`
+
+// Extra instruction for Go files
+const explainGoInstruction = "This is a Go file: list every exported symbol in key_symbols and set exported accordingly."
+
+// Explanations are wrapped to this width
+const explainWidth = 80
+
+func handleExplain(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	file := ""
+	from, to := 0, 0
+	model := getConfig().DefaultModel
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--lines":
+			if i+1 >= len(args) {
+				logError("--lines needs a range, e.g. 10-50")
+				return
+			}
+			var err error
+			from, to, err = parseLineRange(args[i+1])
+			if err != nil {
+				logError(err.Error())
+				return
+			}
+			i++
+		case "--fast", "--smart", "--deep":
+			model = strings.TrimPrefix(args[i], "--")
+		default:
+			file = args[i]
+		}
+	}
+
+	if file == "" {
+		logError("Usage: keke explain <file> [--lines 10-50]")
+		return
+	}
+
+	if !ensurePermission("read", file, fmt.Sprintf("Send %s to the AI for an explanation?", file)) {
+		return
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", file, err))
+		return
+	}
+	if isBinary(content) {
+		logError(fmt.Sprintf("%s is a binary file", file))
+		return
+	}
+
+	lines := splitLines(string(content))
+	if from > 0 {
+		if from > len(lines) {
+			logError(fmt.Sprintf("%s has only %d lines", file, len(lines)))
+			return
+		}
+		if to > len(lines) {
+			to = len(lines)
+		}
+		lines = lines[from-1 : to]
+	}
+
+	code := strings.Join(lines, "\n")
+	if len(code) > maxToolOutputBytes {
+		logError(fmt.Sprintf("%s is too large to explain (%s, limit %s). Narrow it with --lines",
+			file, formatBytes(int64(len(code))), formatBytes(maxToolOutputBytes)))
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("AI explaining %s...", file))
+
+	instruction := explainInstruction
+	if filepath.Ext(file) == ".go" {
+		instruction += "\n" + explainGoInstruction
+	}
+
+	payload := map[string]interface{}{
+		"conversation": []map[string]string{
+			{"role": "user", "content": fmt.Sprintf("%s\n\nFile: %s\n%s", instruction, file, code)},
+		},
+		"model": model,
+		"mode":  "explain", // Explain only, no actions
+	}
+
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		logRequestError("AI error", err)
+		return
+	}
+
+	var explanation Explanation
+	if err := decodeJSONReply(response.Message, &explanation); err != nil {
+		// Fall back to the plain answer
+		printMessage(response.Message)
+		return
+	}
+
+	if jsonMode {
+		emitJSON(explanation)
+		return
+	}
+
+	displayExplanation(&explanation)
+	printDivider()
+	logInfo(fmt.Sprintf("Credits used: %d", response.CreditsUsed))
+}
+
+// parseLineRange parses "10-50" (or a single line "10") into 1-based bounds
+func parseLineRange(value string) (int, int, error) {
+	start, end, found := strings.Cut(value, "-")
+	from, err := strconv.Atoi(start)
+	to := from
+	if err == nil && found {
+		to, err = strconv.Atoi(end)
+	}
+	if err != nil || from < 1 || to < from {
+		return 0, 0, fmt.Errorf("invalid --lines range: %s (use e.g. 10-50)", value)
+	}
+	return from, to, nil
+}
+
+func displayExplanation(e *Explanation) {
+	printDivider()
+
+	fmt.Printf("%s━━━ Purpose ━━━%s\n", dim, reset)
+	for _, line := range wrapText(e.Purpose, explainWidth) {
+		fmt.Println(line)
+	}
+	fmt.Println()
+
+	if len(e.KeySymbols) > 0 {
+		fmt.Printf("%s━━━ Key Types & Functions ━━━%s\n", dim, reset)
+		for _, symbol := range e.KeySymbols {
+			marker := ""
+			if symbol.Exported {
+				marker = fmt.Sprintf(" %s[exported]%s", green, reset)
+			}
+			fmt.Printf("  %s%s%s %s(%s)%s%s\n", bold, symbol.Name, reset, dim, symbol.Kind, reset, marker)
+			for _, line := range wrapText(symbol.Description, explainWidth-4) {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+		fmt.Println()
+	}
+
+	printExplainList("Dependencies", e.Dependencies)
+	printExplainList("Notable Patterns", e.Patterns)
+}
+
+func printExplainList(title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Printf("%s━━━ %s ━━━%s\n", dim, title, reset)
+	for _, item := range items {
+		for i, line := range wrapText(item, explainWidth-4) {
+			if i == 0 {
+				fmt.Printf("  %s•%s %s\n", cyan, reset, line)
+			} else {
+				fmt.Printf("    %s\n", line)
+			}
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ─── EXPORT ──────────────────────────────────────────────────────────────────
+// Bundles a session into keke-export-<timestamp>.tar.gz: the conversation,
+// every file the AI wrote, and the snapshots taken of them
+
+func handleExport(args []string) {
+	format := "md"
+	sessionID := ""
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--format":
+			if i+1 >= len(args) || (args[i+1] != "md" && args[i+1] != "json") {
+				logError("--format must be md or json")
+				return
+			}
+			format = args[i+1]
+			i++
+		case "--session":
+			if i+1 >= len(args) {
+				logError("--session needs a session id (see 'keke sessions list')")
+				return
+			}
+			sessionID = args[i+1]
+			i++
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke export [--session <id>] [--format md|json]")
+			return
+		}
+	}
+
+	session, err := loadSession(sessionID)
+	if err != nil {
+		logError(fmt.Sprintf("Nothing to export: %v", err))
+		return
+	}
+
+	timestamp := time.Now().Format(snapshotTimeFormat)
+	root := "keke-export-" + timestamp
+	archivePath := root + ".tar.gz"
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+
+	// Conversation
+	if format == "json" {
+		data, _ := json.MarshalIndent(session, "", "  ")
+		err = addToTar(tw, root+"/conversation.json", data)
+	} else {
+		err = addToTar(tw, root+"/conversation.md", []byte(conversationMarkdown(session)))
+	}
+	if err != nil {
+		logError(fmt.Sprintf("Failed to write archive: %v", err))
+		return
+	}
+
+	// Files written during the session, as they are now
+	files := 0
+	for _, path := range session.FilesWritten {
+		full := path
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(session.Project, path)
+		}
+		content, err := os.ReadFile(full)
+		if err != nil {
+			logWarning(fmt.Sprintf("Skipping %s: %v", path, err))
+			continue
+		}
+		if err := addToTar(tw, root+"/files/"+exportName(path), content); err != nil {
+			logError(fmt.Sprintf("Failed to write archive: %v", err))
+			return
+		}
+		files++
+	}
+
+	// Snapshots of those files
+	snaps := 0
+	if snapshots, err := loadSnapshots(); err == nil {
+		for _, path := range session.FilesWritten {
+			for _, snap := range snapshotsOf(snapshots, path) {
+				content, err := readSnapshot(snap)
+				if err != nil {
+					continue
+				}
+				name := strings.TrimSuffix(filepath.ToSlash(snap.SnapshotFile), ".gz")
+				if err := addToTar(tw, root+"/snapshots/"+name, content); err != nil {
+					logError(fmt.Sprintf("Failed to write archive: %v", err))
+					return
+				}
+				snaps++
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		logError(fmt.Sprintf("Failed to write archive: %v", err))
+		return
+	}
+	if err := gzw.Close(); err != nil {
+		logError(fmt.Sprintf("Failed to write archive: %v", err))
+		return
+	}
+
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		logError(fmt.Sprintf("Failed to save %s: %v", archivePath, err))
+		return
+	}
+
+	logSuccess(fmt.Sprintf("Exported session %s to %s", session.ID, archivePath))
+	logInfo(fmt.Sprintf("%d messages, %d files, %d snapshots (%s)",
+		len(session.History), files, snaps, formatBytes(int64(buf.Len()))))
+}
+
+// conversationMarkdown renders a session as a readable transcript
+func conversationMarkdown(session *SessionData) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Keke session %s\n\n", session.ID)
+	fmt.Fprintf(&b, "- Mode: %s\n", session.Mode)
+	fmt.Fprintf(&b, "- Model: %s\n", session.Model)
+	fmt.Fprintf(&b, "- Project: %s\n", session.Project)
+	fmt.Fprintf(&b, "- Started: %s\n", session.CreatedAt.Format(time.RFC1123))
+	fmt.Fprintf(&b, "- Credits used: %d\n", session.CreditsUsed)
+
+	for _, msg := range session.History {
+		content := msg["content"]
+		switch {
+		case msg["role"] == "assistant":
+			fmt.Fprintf(&b, "\n## Keke\n\n%s\n", content)
+		case strings.HasPrefix(content, "Action result: "):
+			fmt.Fprintf(&b, "\n### Action result\n\n```\n%s\n```\n", strings.TrimPrefix(content, "Action result: "))
+		default:
+			fmt.Fprintf(&b, "\n## You\n\n%s\n", content)
+		}
+	}
+	return b.String()
+}
+
+// exportName turns a written path into a safe location inside the archive
+func exportName(path string) string {
+	name := filepath.ToSlash(filepath.Clean(path))
+	name = strings.TrimPrefix(name, "/")
+	return strings.ReplaceAll(name, "../", "")
+}
+
+// addToTar writes one regular file entry (the reverse of extractTarGz)
+func addToTar(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
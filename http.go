@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── HTTP CLIENT ─────────────────────────────────────────────────────────────
+// Every request goes through one transport so proxy settings apply to the
+// whole tool: --proxy, else KEKE_PROXY, else HTTP_PROXY/HTTPS_PROXY/NO_PROXY.
+// Calls that wait on a model get the longer ai_timeout_seconds to start
+// answering, after which a streamed reply may take as long as it needs; the
+// rest (login, whoami, credits...) http_timeout_seconds in total.
+// --http-timeout sets both
+
+var (
+	sharedClient    *http.Client
+	sharedAIClient  *http.Client
+	sharedTransport *http.Transport
+	httpOnce        sync.Once
+)
+
+// Endpoints whose answers are generated by a model
+var aiEndpoints = []string{EndpointAI, EndpointSignal, EndpointBacktest}
+
+// httpClient returns the shared client, timing out after http_timeout_seconds
+func httpClient() *http.Client {
+	httpOnce.Do(initHTTP)
+	return sharedClient
+}
+
+// aiClient is the shared client for model calls, timing out when no
+// response headers arrive within ai_timeout_seconds
+func aiClient() *http.Client {
+	httpOnce.Do(initHTTP)
+	return sharedAIClient
+}
+
+// clientFor picks the client for a request to url
+func clientFor(url string) *http.Client {
+	for _, endpoint := range aiEndpoints {
+		if strings.HasSuffix(url, endpoint) {
+			return aiClient()
+		}
+	}
+	return httpClient()
+}
+
+// downloadClient shares the transport but has no overall timeout, for large
+// downloads such as 'keke upgrade'
+func downloadClient() *http.Client {
+	httpOnce.Do(initHTTP)
+	return &http.Client{Transport: sharedTransport}
+}
+
+func initHTTP() {
+	sharedTransport = http.DefaultTransport.(*http.Transport).Clone()
+	sharedTransport.Proxy = proxyFunc()
+	timeout, aiTimeout := getConfig().HTTPTimeoutSeconds, getConfig().AITimeoutSeconds
+	if httpTimeoutFlag > 0 {
+		timeout, aiTimeout = httpTimeoutFlag, httpTimeoutFlag
+	}
+	sharedClient = &http.Client{
+		Transport: sharedTransport,
+		Timeout:   time.Duration(timeout) * time.Second,
+	}
+
+	// No overall Timeout: it would cut a long SSE stream off mid-reply
+	aiTransport := sharedTransport.Clone()
+	aiTransport.ResponseHeaderTimeout = time.Duration(aiTimeout) * time.Second
+	sharedAIClient = &http.Client{Transport: aiTransport}
+}
+
+// proxyFunc picks the proxy for every request. An invalid override is
+// reported and the environment is used instead
+func proxyFunc() func(*http.Request) (*url.URL, error) {
+	override := proxyFlag
+	if override == "" {
+		override = os.Getenv("KEKE_PROXY")
+	}
+	if override == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	proxy, err := parseProxyURL(override)
+	if err != nil {
+		logWarning(fmt.Sprintf("Ignoring proxy %s: %v", override, err))
+		return http.ProxyFromEnvironment
+	}
+	return http.ProxyURL(proxy)
+}
+
+// parseProxyURL accepts "host:port" as well as a full URL
+func parseProxyURL(value string) (*url.URL, error) {
+	proxy, err := url.Parse(value)
+	if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+		proxy, err = url.Parse("http://" + value)
+	}
+	if err != nil || proxy.Host == "" {
+		return nil, fmt.Errorf("not a proxy URL")
+	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+		return proxy, nil
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %s", proxy.Scheme)
+}
+
+// ─── REDACTION ───────────────────────────────────────────────────────────────
+// Tokens and the PC hash must never reach the terminal, verbose logs or error
+// messages. Headers are masked when logged, and server bodies quoted in
+// errors have their secret-looking fields masked
+
+// Headers whose values are masked
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"X-Pc-Hash":     true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// JSON fields whose values are masked, matched as parts of the field name
+var sensitiveFieldParts = []string{"token", "secret", "password", "hash", "key", "authorization", "cookie"}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)([^\s"',]+)`)
+	jwtPattern    = regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]+`)
+)
+
+// Server bodies quoted in errors are cut to this many bytes
+const maxErrorBodySize = 500
+
+// redact keeps the first and last 4 characters of a secret
+func redact(s string) string {
+	if len(s) <= 12 {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:4] + "…" + s[len(s)-4:]
+}
+
+// redactHeader masks the value of a sensitive header, keeping the
+// "Bearer " scheme readable
+func redactHeader(name, value string) string {
+	if !sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+		return value
+	}
+	if scheme, token, ok := strings.Cut(value, " "); ok {
+		return scheme + " " + redact(token)
+	}
+	return redact(value)
+}
+
+// logRequestHeaders prints the headers of req in verbose mode, masked
+func logRequestHeaders(req *http.Request) {
+	if logLevel < levelVerbose {
+		return
+	}
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		logVerbose(fmt.Sprintf("  %s: %s", name, redactHeader(name, req.Header.Get(name))))
+	}
+}
+
+// redactBody returns a server response body safe to print: secret-looking
+// JSON fields, bearer tokens and JWTs are masked and long bodies are cut
+func redactBody(body []byte) string {
+	var data interface{}
+	text := string(body)
+	if json.Unmarshal(body, &data) == nil {
+		if clean, err := json.Marshal(redactJSON(data)); err == nil {
+			text = string(clean)
+		}
+	}
+	text = bearerPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := bearerPattern.FindStringSubmatch(match)
+		return parts[1] + redact(parts[2])
+	})
+	text = jwtPattern.ReplaceAllStringFunc(text, redact)
+
+	text = strings.TrimSpace(text)
+	if len(text) > maxErrorBodySize {
+		text = text[:maxErrorBodySize] + "…"
+	}
+	return text
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, inner := range v {
+			if s, ok := inner.(string); ok && sensitiveField(field) {
+				v[field] = redact(s)
+				continue
+			}
+			v[field] = redactJSON(inner)
+		}
+	case []interface{}:
+		for i, inner := range v {
+			v[i] = redactJSON(inner)
+		}
+	}
+	return value
+}
+
+func sensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveFieldParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ─── IGNORE RULES ────────────────────────────────────────────────────────────
+// Paths hidden from the AI. Read from .kekeignore (gitignore syntax) at the
+// project root; without one, built-in defaults plus .gitignore are used.
+
+const kekeignoreFile = ".kekeignore"
+
+// Always hidden, whatever the ignore files say
+var alwaysIgnored = []string{".keke/", ".git/"}
+
+// Used when there is no .kekeignore
+var defaultIgnored = []string{"node_modules/"}
+
+// Written by 'keke init'
+const defaultKekeignore = `# Paths Keke never lists or reads (gitignore syntax)
+node_modules/
+dist/
+build/
+vendor/
+*.min.js
+__pycache__/
+*.pyc
+.venv/
+`
+
+// loadIgnorePatterns returns the active ignore patterns for this project
+func loadIgnorePatterns() []string {
+	patterns := append([]string{}, alwaysIgnored...)
+
+	if lines, err := readIgnoreFile(kekeignoreFile); err == nil {
+		return append(patterns, lines...)
+	}
+
+	patterns = append(patterns, defaultIgnored...)
+	if lines, err := readIgnoreFile(".gitignore"); err == nil {
+		patterns = append(patterns, lines...)
+	}
+	return patterns
+}
+
+func readIgnoreFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns, nil
+}
+
+// isIgnored reports whether path (relative to the project root or absolute)
+// is excluded by patterns. The last matching pattern wins, so "!keep.txt"
+// re-includes a file
+func isIgnored(path string, isDir bool, patterns []string) bool {
+	rel := relativeToProject(path)
+	if rel == "." {
+		return false
+	}
+
+	// A path is also ignored when any parent directory is
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if matchIgnore(strings.Join(parts[:i], "/"), true, patterns) {
+			return true
+		}
+	}
+	return matchIgnore(rel, isDir, patterns)
+}
+
+func matchIgnore(rel string, isDir bool, patterns []string) bool {
+	ignored := false
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+
+		dirOnly := strings.HasSuffix(pattern, "/")
+		pattern = strings.TrimSuffix(pattern, "/")
+		if dirOnly && !isDir {
+			continue
+		}
+
+		pattern = strings.TrimPrefix(pattern, "**/")
+
+		var matched bool
+		if strings.Contains(pattern, "/") {
+			// Anchored to the project root
+			matched, _ = filepath.Match(strings.TrimPrefix(pattern, "/"), rel)
+		} else {
+			// Matches the name at any depth
+			matched, _ = filepath.Match(pattern, rel[strings.LastIndex(rel, "/")+1:])
+		}
+
+		if matched {
+			ignored = !negate
+		}
+	}
+	return ignored
+}
+
+// relativeToProject returns path relative to the working directory using "/"
+// separators
+func relativeToProject(path string) string {
+	if filepath.IsAbs(path) {
+		if cwd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(cwd, path); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// ─── SENSITIVE FILES ─────────────────────────────────────────────────────────
+// Files that usually hold secrets. Reading one sends it to the backend, so
+// it needs a second, typed confirmation after the usual permission check.
+// The never_read_patterns config key adds the user's own patterns to the list
+
+var sensitivePatterns = []string{"*.pem", "*.key", ".env*", "*secret*", "credentials*", "*.pfx", "*.p12", "id_rsa", "id_ed25519", "id_ecdsa"}
+
+// isSensitiveFile reports whether the file name of path matches one of the
+// built-in secret patterns or never_read_patterns (case-insensitive)
+func isSensitiveFile(path string) bool {
+	return matchesFileName(path, sensitivePatterns) || matchesFileName(path, getConfig().NeverReadPatterns)
+}
+
+func matchesFileName(path string, patterns []string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// confirmSensitiveRead warns about sending path to the backend and asks for
+// 'yes' typed in full. Like dangerous commands, --yes alone refuses and
+// only --yes --force reads it unasked
+func confirmSensitiveRead(path string) bool {
+	fmt.Println()
+	logWarning(fmt.Sprintf("SENSITIVE FILE: %s", path))
+	fmt.Println("  It looks like it holds secrets (keys, passwords, tokens). Reading it")
+	fmt.Println("  sends its content to the AI backend and into the saved session history.")
+	fmt.Println()
+
+	if assumeYes {
+		if forceFlag {
+			logWarning("Reading it because of --yes --force")
+			return true
+		}
+		logError("Sensitive file refused: --yes does not cover it, add --force to allow")
+		return false
+	}
+
+	if prompt("Type 'yes' to let the AI read it anyway") != "yes" {
+		logError("Sensitive file refused")
+		return false
+	}
+	return true
+}
@@ -1,55 +1,48 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
+// Contents of a fresh changelog.md
+const defaultChangelog = `# Keke Changelog
+
+All changes made by Keke will be logged here.
+This file helps you understand what changed and why.
+
+---
+`
+
 func handleInit() {
 	if isProjectInitialized() {
+		if forceFlag { // --force is a global flag
+			repairProject()
+			return
+		}
 		logWarning("This project is already initialized (.keke/ exists)")
 		logInfo("Run 'keke login' if you haven't logged in yet")
+		logInfo("Run 'keke init --force' to recreate missing or damaged files")
 		return
 	}
 
 	logInfo("Initializing Keke in this project...")
 
-	// Create .keke/
-	if err := os.MkdirAll(projectDir(), 0755); err != nil {
-		logError(fmt.Sprintf("Failed to create .keke/: %v", err))
-		return
-	}
-
-	// Create snapshots/
-	if err := os.MkdirAll(projectSnapshotsDir(), 0755); err != nil {
-		logError(fmt.Sprintf("Failed to create snapshots/: %v", err))
+	if _, err := ensureProjectFiles(); err != nil {
+		logError(err.Error())
 		return
 	}
 
-	// Create permissions.json (empty for now, server validates)
-	perms := &Permissions{}
-	if err := writePermissions(perms); err != nil {
-		logError(fmt.Sprintf("Failed to create permissions.json: %v", err))
-		return
-	}
-
-	// Create changelog.md
-	changelog := `# Keke Changelog
-
-All changes made by Keke will be logged here.
-This file helps you understand what changed and why.
-
----
-`
-	if err := os.WriteFile(projectChangelogFile(), []byte(changelog), 0644); err != nil {
-		logError(fmt.Sprintf("Failed to create changelog.md: %v", err))
-		return
-	}
-
-	// Create context.json (AI memory - managed by server)
-	if err := os.WriteFile(projectContextFile(), []byte("{}\n"), 0644); err != nil {
-		logError(fmt.Sprintf("Failed to create context.json: %v", err))
-		return
+	// Create .kekeignore (project root) unless the user already has one
+	createdIgnore := false
+	if _, err := os.Stat(kekeignoreFile); os.IsNotExist(err) {
+		if err := os.WriteFile(kekeignoreFile, []byte(defaultKekeignore), 0644); err != nil {
+			logWarning(fmt.Sprintf("Failed to create %s: %v", kekeignoreFile, err))
+		} else {
+			createdIgnore = true
+		}
 	}
 
 	// Add .keke/ to .gitignore if git repo exists
@@ -64,6 +57,9 @@ This file helps you understand what changed and why.
 	logInfo("  snapshots/        — file backups for rollback")
 	logInfo("  changelog.md      — auto-generated change log")
 	logInfo("  context.json      — AI working memory")
+	if createdIgnore {
+		logInfo("Created .kekeignore — paths the AI never lists or reads")
+	}
 	printDivider()
 
 	if !isLoggedIn() {
@@ -73,6 +69,93 @@ This file helps you understand what changed and why.
 	}
 }
 
+// repairProject is 'keke init --force' on an initialized project: it brings
+// back what is missing or broken and leaves snapshots and sessions alone
+func repairProject() {
+	logInfo("Checking .keke/ for missing or damaged files...")
+	changes, err := ensureProjectFiles()
+	for _, change := range changes {
+		logInfo("  " + change)
+	}
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	if len(changes) == 0 {
+		logSuccess("Nothing to repair")
+		return
+	}
+	logSuccess(fmt.Sprintf("Repaired %d files", len(changes)))
+}
+
+// ensureProjectFiles creates whatever is missing from .keke/ and replaces
+// JSON files that no longer parse, keeping the damaged copy as
+// <name>.corrupt. It returns one line per file it changed
+func ensureProjectFiles() ([]string, error) {
+	var changes []string
+
+	// Create .keke/ and snapshots/
+	for _, dir := range []string{projectDir(), projectSnapshotsDir()} {
+		if _, err := os.Stat(dir); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return changes, fmt.Errorf("Failed to create %s/: %v", filepath.Base(dir), err)
+		}
+		changes = append(changes, fmt.Sprintf("Created %s/", filepath.Base(dir)))
+	}
+
+	// permissions.json starts empty (server validates), context.json is the
+	// AI memory edited with 'keke context'
+	emptyPerms, _ := json.MarshalIndent(&Permissions{Read: []string{}, Write: []string{}, Execute: []string{}}, "", "  ")
+	// target is what a JSON file must decode into, nil for other files
+	files := []struct {
+		path    string
+		content []byte
+		target  interface{}
+	}{
+		{projectPermissionsFile(), emptyPerms, &Permissions{}},
+		{projectChangelogFile(), []byte(defaultChangelog), nil},
+		{projectContextFile(), []byte("{}\n"), &map[string]interface{}{}},
+	}
+
+	for _, f := range files {
+		name := filepath.Base(f.path)
+		data, err := os.ReadFile(f.path)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.WriteFile(f.path, f.content, 0644); err != nil {
+				return changes, fmt.Errorf("Failed to create %s: %v", name, err)
+			}
+			changes = append(changes, fmt.Sprintf("Created %s", name))
+		case err != nil:
+			return changes, fmt.Errorf("Cannot read %s: %v", name, err)
+		case f.target != nil && json.Unmarshal(data, f.target) != nil:
+			backup := corruptBackupPath(f.path)
+			if err := os.Rename(f.path, backup); err != nil {
+				return changes, fmt.Errorf("Failed to move damaged %s aside: %v", name, err)
+			}
+			if err := os.WriteFile(f.path, f.content, 0644); err != nil {
+				return changes, fmt.Errorf("Failed to recreate %s: %v", name, err)
+			}
+			changes = append(changes, fmt.Sprintf("Recreated %s (invalid JSON, old copy kept as %s)", name, filepath.Base(backup)))
+		}
+	}
+	return changes, nil
+}
+
+// corruptBackupPath returns path.corrupt, or path.corrupt.N when earlier
+// backups exist, so none is overwritten
+func corruptBackupPath(path string) string {
+	backup := path + ".corrupt"
+	for n := 1; ; n++ {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			return backup
+		}
+		backup = fmt.Sprintf("%s.corrupt.%d", path, n)
+	}
+}
+
 func addToGitignore() {
 	gitignorePath := ".gitignore"
 	
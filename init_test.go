@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestEnsureProjectFilesReplacesInvalidJSON(t *testing.T) {
+	newTestProject(t)
+	if _, err := ensureProjectFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Valid JSON of the wrong shape is as unusable as a syntax error
+	for _, content := range []string{"[]", `"x"`, "{broken"} {
+		writeTestFile(t, projectPermissionsFile(), content)
+		if _, err := ensureProjectFiles(); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(readTestFile(t, projectPermissionsFile())), &Permissions{}); err != nil {
+			t.Errorf("permissions.json not recreated after %q: %v", content, err)
+		}
+	}
+
+	// Every damaged copy is kept
+	for _, backup := range []string{".corrupt", ".corrupt.1", ".corrupt.2"} {
+		if _, err := os.Stat(projectPermissionsFile() + backup); err != nil {
+			t.Errorf("backup %s missing: %v", backup, err)
+		}
+	}
+	if got := readTestFile(t, projectPermissionsFile()+".corrupt"); got != "[]" {
+		t.Errorf("first backup = %q, want %q", got, "[]")
+	}
+}
@@ -2,13 +2,14 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 )
 
-// ANSI color codes
-const (
+// ANSI color codes (emptied by disableColors)
+var (
 	reset   = "\033[0m"
 	red     = "\033[31m"
 	green   = "\033[32m"
@@ -19,27 +20,165 @@ const (
 	dim     = "\033[2m"
 )
 
+// colorEnabled is false once disableColors has run
+var colorEnabled = true
+
+// disableColors turns all styling into plain text
+func disableColors() {
+	colorEnabled = false
+	reset, red, green, yellow, cyan, magenta, bold, dim = "", "", "", "", "", "", "", ""
+}
+
+// wantColor decides whether to style output: NO_COLOR (no-color.org), the
+// no_color config key, --no-color, --json and non-terminal stdout turn it off
+func wantColor(noColorFlag bool) bool {
+	if noColorFlag || jsonMode || getConfig().NoColor {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a character device (a TTY), so piped or
+// redirected output stays plain
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// ─── JSON OUTPUT (--json) ────────────────────────────────────────────────────
+// Log lines and command results are collected and printed as one JSON array
+// when the command finishes, so output can be piped into jq
+
+var jsonMode bool
+
+type jsonEntry struct {
+	Level   string      `json:"level"` // debug, info, success, warning, error, message, result
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+var jsonOutput = []jsonEntry{}
+
+// emitJSON records a command's result data
+func emitJSON(data interface{}) {
+	jsonOutput = append(jsonOutput, jsonEntry{Level: "result", Data: data})
+}
+
+// flushJSON prints everything collected in JSON mode
+func flushJSON() {
+	if !jsonMode {
+		return
+	}
+	data, _ := json.MarshalIndent(jsonOutput, "", "  ")
+	fmt.Println(string(data))
+	jsonOutput = []jsonEntry{}
+}
+
+// ─── LOG LEVEL ───────────────────────────────────────────────────────────────
+// -q keeps warnings, errors and results; -v adds request details. Set with
+// the flags or KEKE_LOG_LEVEL=quiet|normal|verbose
+
+type logLevelValue int
+
+const (
+	levelQuiet logLevelValue = iota
+	levelNormal
+	levelVerbose
+)
+
+var logLevel = levelNormal
+
+func parseLogLevel(value string) (logLevelValue, error) {
+	switch strings.ToLower(value) {
+	case "quiet", "q":
+		return levelQuiet, nil
+	case "normal", "":
+		return levelNormal, nil
+	case "verbose", "v", "debug":
+		return levelVerbose, nil
+	}
+	return levelNormal, fmt.Errorf("unknown log level %q (use quiet, normal or verbose)", value)
+}
+
+// logVerbose prints details only wanted with -v
+func logVerbose(msg string) {
+	if logLevel < levelVerbose {
+		return
+	}
+	if jsonMode {
+		jsonOutput = append(jsonOutput, jsonEntry{Level: "debug", Message: msg})
+		return
+	}
+	fmt.Printf("%s· %s%s\n", dim, msg, reset)
+}
+
+// printMessage shows AI output text
+func printMessage(msg string) {
+	if jsonMode {
+		jsonOutput = append(jsonOutput, jsonEntry{Level: "message", Message: msg})
+		return
+	}
+	fmt.Println(msg)
+}
+
 func logInfo(msg string) {
+	if logLevel == levelQuiet {
+		return
+	}
+	if jsonMode {
+		jsonOutput = append(jsonOutput, jsonEntry{Level: "info", Message: msg})
+		return
+	}
 	fmt.Printf("%s%s►%s %s\n", dim, cyan, reset, msg)
 }
 
 func logSuccess(msg string) {
+	if logLevel == levelQuiet {
+		return
+	}
+	if jsonMode {
+		jsonOutput = append(jsonOutput, jsonEntry{Level: "success", Message: msg})
+		return
+	}
 	fmt.Printf("%s%s✓%s %s\n", bold, green, reset, msg)
 }
 
 func logWarning(msg string) {
+	if jsonMode {
+		jsonOutput = append(jsonOutput, jsonEntry{Level: "warning", Message: msg})
+		return
+	}
 	fmt.Printf("%s%s⚠%s %s\n", bold, yellow, reset, msg)
 }
 
 func logError(msg string) {
+	if exitCode == 0 {
+		exitCode = exitError
+	}
+	if jsonMode {
+		jsonOutput = append(jsonOutput, jsonEntry{Level: "error", Message: msg})
+		return
+	}
 	fmt.Printf("%s%s✗%s %s\n", bold, red, reset, msg)
 }
 
 func printDivider() {
+	if jsonMode || logLevel == levelQuiet {
+		return
+	}
 	fmt.Printf("%s────────────────────────────────────────%s\n", dim, reset)
 }
 
 func printHeader() {
+	if jsonMode || logLevel == levelQuiet {
+		return
+	}
 	fmt.Println()
 	fmt.Printf("%s%s  ██╗  ██╗███████╗██╗  ██╗███████╗%s\n", bold, magenta, reset)
 	fmt.Printf("%s%s  ██║ ██╔╝██╔════╝██║ ██╔╝██╔════╝%s\n", bold, magenta, reset)
@@ -50,16 +189,72 @@ func printHeader() {
 	fmt.Println()
 }
 
+// promptOutput is where prompts are written; stderr in JSON mode so stdout
+// stays valid JSON
+func promptOutput() *os.File {
+	if jsonMode {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
 func prompt(msg string) string {
-	fmt.Printf("%s%s►%s %s ", dim, cyan, reset, msg)
+	fmt.Fprintf(promptOutput(), "%s%s►%s %s ", dim, cyan, reset, msg)
 	var input string
 	fmt.Scanln(&input)
 	return input
 }
 
+// promptYesNo asks a y/n question and reports whether the answer was yes.
+// With --yes it answers yes without reading stdin
+func promptYesNo(msg string) bool {
+	if assumeYes {
+		fmt.Fprintf(promptOutput(), "%s%s►%s %s y (--yes)\n", dim, cyan, reset, msg)
+		return true
+	}
+	response := strings.ToLower(prompt(msg))
+	return response == "y" || response == "yes"
+}
+
 func promptPassword(msg string) string {
-	fmt.Printf("%s%s►%s %s: ", dim, cyan, reset, msg)
+	fmt.Fprintf(promptOutput(), "%s%s►%s %s: ", dim, cyan, reset, msg)
 	reader := bufio.NewReader(os.Stdin)
 	password, _ := reader.ReadString('\n')
 	return strings.TrimSpace(password)
-}
\ No newline at end of file
+}
+
+// formatBytes renders a byte count as B/KB/MB/GB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGT"[exp])
+}
+
+// wrapText breaks s into lines of at most width runes at word boundaries.
+// Existing line breaks are kept
+func wrapText(s string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line == "" {
+				line = word
+			} else {
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
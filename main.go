@@ -1,14 +1,103 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 )
 
 var version = "v0.1.0" // Injected by goreleaser
 
+// Global flags, accepted anywhere on the command line
+var (
+	dryRun          bool   // --dry-run: simulate file writes and commands
+	noColor         bool   // --no-color: plain text output
+	proxyFlag       string // --proxy URL: overrides KEKE_PROXY and HTTP(S)_PROXY
+	httpTimeoutFlag int    // --http-timeout N: seconds, overrides both request timeouts
+
+	sandboxFlag     bool // --sandbox: run AI commands in Docker
+	sandboxRequired bool // --sandbox-required: refuse to run them without Docker
+
+	assumeYes bool // --yes or KEKE_ASSUME_YES: answer yes to confirmations
+	forceFlag bool // --force: overwrite files; with --yes also run dangerous commands
+)
+
+// exitCode is reported to the shell when the command finishes. logError sets
+// it to exitError unless a more specific code has been chosen
+var exitCode int
+
+// Exit codes for scripts and CI
+const (
+	exitError          = 1 // any other failure
+	exitNotLoggedIn    = 2
+	exitNotInitialized = 3
+	exitNoCredits      = 4 // the server answered 402
+	exitNetwork        = 5 // the server could not be reached
+)
+
+// errInsufficientCredits is returned for the server's 402 answer
+var errInsufficientCredits = errors.New("insufficient credits")
+
+// errSessionExpired is returned when the server still answers 401 after a
+// token refresh
+var errSessionExpired = errors.New("session expired")
+
+// logRequestError reports a failed server request, picking the exit code
+// that says why it failed
+func logRequestError(context string, err error) {
+	if errors.Is(err, errSessionExpired) {
+		logError("Session expired. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+	logError(fmt.Sprintf("%s: %v", context, err))
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		logInfo("The server did not answer in time. Allow longer with --http-timeout N, or 'keke config set ai_timeout_seconds N'")
+	}
+	exitCode = requestExitCode(err)
+}
+
+func requestExitCode(err error) int {
+	var urlErr *url.Error
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errInsufficientCredits):
+		return exitNoCredits
+	case errors.Is(err, errSessionExpired):
+		return exitNotLoggedIn
+	case errors.As(err, &urlErr), errors.As(err, &netErr):
+		return exitNetwork
+	}
+	return exitError
+}
+
 func main() {
-	args := os.Args[1:]
+	if value := os.Getenv("KEKE_LOG_LEVEL"); value != "" {
+		level, err := parseLogLevel(value)
+		if err != nil {
+			logWarning(fmt.Sprintf("Ignoring KEKE_LOG_LEVEL: %v", err))
+		}
+		logLevel = level
+	}
+	args := parseGlobalFlags(os.Args[1:]) // -q and -v override KEKE_LOG_LEVEL
+	if value := os.Getenv("KEKE_ASSUME_YES"); value != "" && value != "0" && value != "false" {
+		assumeYes = true
+	}
+
+	if !wantColor(noColor) {
+		disableColors()
+	}
+	defer finish()
+
+	if err := checkProvider(providerFlag); err != nil {
+		logError(fmt.Sprintf("Invalid --provider: %v", err))
+		exitCode = exitError
+		return
+	}
 
 	if len(args) == 0 {
 		showHelp()
@@ -16,32 +105,74 @@ func main() {
 	}
 
 	command := args[0]
+	if command != "config" && command != "__complete" {
+		warnConfigProblems()
+	}
 
 	switch command {
 	case "version", "--version", "-v":
-		fmt.Println(version)
+		handleVersion()
 
 	case "init":
 		handleInit()
 
+	case "scaffold":
+		handleScaffold(args[1:])
+
 	case "signup":
-		handleSignup()
+		handleSignup(args[1:])
 
 	case "login":
-		handleLogin()
+		handleLogin(args[1:])
 
 	case "logout":
 		handleLogout()
 
 	case "whoami":
-		handleWhoami()
+		handleWhoami(args[1:])
 
 	case "credits":
-		handleCredits()
+		handleCredits(args[1:])
+
+	case "status":
+		handleStatus()
+
+	case "session":
+		handleSession(args[1:])
+
+	case "sessions":
+		handleSessions(args[1:])
+
+	case "export":
+		handleExport(args[1:])
+
+	case "plan":
+		handlePlan(args[1:])
+
+	case "migrate":
+		handleMigrate(args[1:])
+
+	case "docs":
+		handleDocs(args[1:])
+
+	case "explain":
+		handleExplain(args[1:])
+
+	case "search":
+		handleSearch(args[1:])
+
+	case "test":
+		handleTest(args[1:])
+
+	case "review":
+		handleReview(args[1:])
 
 	case "ask":
 		handleAsk(args[1:])
 
+	case "repl":
+		handleRepl(args[1:])
+
 	case "research":
 		handleResearch(args[1:])
 
@@ -51,8 +182,44 @@ func main() {
 	case "rollback":
 		handleRollback(args[1:])
 
+	case "diff":
+		handleDiff(args[1:])
+
+	case "snapshots":
+		handleSnapshots(args[1:])
+
+	case "context":
+		handleContext(args[1:])
+
+	case "clean":
+		handleClean(args[1:])
+
+	case "snapshot":
+		handleSnapshot(args[1:])
+
+	case "permissions":
+		handlePermissions(args[1:])
+
+	case "audit":
+		handleAudit(args[1:])
+
+	case "changelog":
+		handleChangelog(args[1:])
+
+	case "sandbox":
+		handleSandbox(args[1:])
+
 	case "upgrade":
-		handleUpgrade()
+		handleUpgrade(args[1:])
+
+	case "config":
+		handleConfig(args[1:])
+
+	case "completion":
+		handleCompletion(args[1:])
+
+	case "__complete": // used by the completion scripts
+		handleCompleteHelper(args[1:])
 
 	case "help", "--help", "-h":
 		showHelp()
@@ -60,10 +227,86 @@ func main() {
 	default:
 		logError(fmt.Sprintf("Unknown command: %s", command))
 		logInfo("Run 'keke help' for available commands")
-		os.Exit(1)
+		exitCode = exitError
 	}
 }
 
+// handleVersion prints the version, as a result entry with --json
+func handleVersion() {
+	if jsonMode {
+		emitJSON(map[string]string{"version": version})
+		return
+	}
+	fmt.Println(version)
+}
+
+// finish prints collected JSON output and exits with exitCode
+func finish() {
+	printDryRunSummary()
+	flushJSON()
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// parseGlobalFlags records global flags and returns the remaining arguments
+func parseGlobalFlags(args []string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dry-run":
+			dryRun = true
+		case "--json":
+			jsonMode = true
+		case "--no-color":
+			noColor = true
+		case "--no-stream":
+			streamEnabled = false
+		case "--sandbox":
+			sandboxFlag = true
+		case "--sandbox-required":
+			sandboxRequired = true
+		case "--yes", "-y":
+			assumeYes = true
+		case "--force":
+			forceFlag = true
+		case "--quiet", "-q":
+			logLevel = levelQuiet
+		case "--verbose":
+			logLevel = levelVerbose
+		case "-v":
+			// Alone, -v still prints the version
+			if len(args) == 1 {
+				rest = append(rest, args[i])
+			} else {
+				logLevel = levelVerbose
+			}
+		case "--proxy":
+			if i+1 < len(args) {
+				proxyFlag = args[i+1]
+				i++
+			}
+		case "--provider":
+			if i+1 < len(args) {
+				providerFlag = args[i+1]
+				i++
+			}
+		case "--http-timeout":
+			if i+1 < len(args) {
+				if seconds, err := strconv.Atoi(args[i+1]); err == nil && seconds > 0 {
+					httpTimeoutFlag = seconds
+				} else {
+					logWarning(fmt.Sprintf("Ignoring --http-timeout %s: not a positive number of seconds", args[i+1]))
+				}
+				i++
+			}
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return rest
+}
+
 func showHelp() {
 	printHeader()
 	logInfo("AI agent for software + ML research in your terminal")
@@ -72,37 +315,84 @@ func showHelp() {
 
 	fmt.Println("  SOFTWARE DEVELOPMENT")
 	fmt.Println()
-	printCmd("init", "Initialize Keke in this project")
-	printCmd("ask", "AI coding assistant (--fast/--smart/--deep)")
-	printCmd("rollback", "Restore file from snapshot")
+	printCmd("init", "Initialize Keke in this project (--force: repair missing or damaged files)")
+	printCmd("scaffold", "Start a project from a template (no name: list them)")
+	printCmd("ask", "AI coding assistant (--fast/--smart/--deep, --interactive, --file F, --continue, --max-steps N, --budget N, --no-git, --no-project)")
+	printCmd("repl", "Interactive ask session, takes the ask flags (/model, /clear, /exit)")
+	printCmd("plan", "Show the AI's plan only (run it with ask --use-plan)")
+	printCmd("review", "AI code review of a file")
+	printCmd("test", "Run the test suite and let the AI fix failures")
+	printCmd("search", "Ask the AI where something is in the code")
+	printCmd("explain", "Explain a file (--lines 10-50)")
+	printCmd("docs", "Add doc comments and write DOCS.md (--format md|html|json)")
+	printCmd("migrate", "Port code (--from python@2 --to python@3)")
+	printCmd("rollback", "Restore from snapshots (--all: undo last session, --at T, --before T, --latest, --list, --filter GLOB, --preview)")
+	printCmd("diff", "Changes made by the last session (--stat), or: diff <file> against a snapshot")
+	printCmd("snapshots", "List and inspect snapshots (prune --keep N --older-than 7d)")
+	printCmd("snapshot", "Save/restore named snapshots")
+	printCmd("clean", "Delete old snapshots and sessions (--older-than N, --compress)")
+	printCmd("context", "View or edit facts the AI remembers")
+	printCmd("permissions", "Review or revoke granted permissions")
+	printCmd("audit", "What the AI read, wrote and ran (--since 1d, --action TYPE)")
+	printCmd("changelog", "Files changed and commands run by the AI (--since 1d)")
 	fmt.Println()
 
 	fmt.Println("  ML RESEARCH")
 	fmt.Println()
-	printCmd("research", "AI research assistant for experiments & analysis")
+	printCmd("research", "AI research assistant (--dataset data.csv: preload it, --output notes.ipynb: save as notebook; compare m1 m2: benchmark two models)")
 	fmt.Println()
 
 	fmt.Println("  TRADING")
 	fmt.Println()
-	printCmd("signal", "Forex market analysis & predictions")
+	printCmd("signal", "Forex market analysis & predictions (--multi, --filter BUY, --alert EMAIL)")
+	printCmd("signal watch", "Manage and run a watchlist of pairs")
+	printCmd("signal history", "Past predictions (--limit N, --clear, --mark-outcome ID win|loss)")
+	printCmd("signal backtest", "Replay the model on past prices (<PAIR> <from> <to>)")
 	fmt.Println()
 
 	fmt.Println("  ACCOUNT")
 	fmt.Println()
-	printCmd("signup", "Create new account")
-	printCmd("login", "Log in (Email or Gmail)")
+	printCmd("signup", "Create new account (password or magic link, --port N)")
+	printCmd("login", "Log in (Email or Gmail, --port N)")
 	printCmd("logout", "Log out")
-	printCmd("whoami", "Show account info")
-	printCmd("credits", "Check credit balance")
+	printCmd("whoami", "Show account info (cached for 60s, --fresh to refetch)")
+	printCmd("credits", "Check credit balance (budget set N: cap per session)")
+	printCmd("status", "Project, session and account overview")
+	printCmd("session", "Current session and its 1h expiry (clear: forget it)")
+	printCmd("sessions", "List recent conversations (resume with --session)")
+	printCmd("export", "Archive a session with its written files")
 	fmt.Println()
 
 	fmt.Println("  SYSTEM")
 	fmt.Println()
-	printCmd("upgrade", "Update to latest version")
+	printCmd("config", "Get/set preferences (keke config list, keke config validate)")
+	printCmd("sandbox test", "Check that Docker can run sandboxed commands")
+	printCmd("upgrade", "Update to latest version (--check, --version vX.Y.Z, --channel beta, --rollback)")
+	printCmd("completion", "Print a bash, zsh or fish completion script (source <(keke completion bash))")
 	printCmd("version", "Show version")
 	printCmd("help", "Show this help")
 	fmt.Println()
 
+	fmt.Println("  GLOBAL FLAGS")
+	fmt.Println()
+	printCmd("--dry-run", "Show file writes and commands without running them")
+	printCmd("--json", "Machine-readable JSON output")
+	printCmd("--no-color", "Plain text output (also NO_COLOR, or when piped)")
+	printCmd("--no-stream", "Print AI replies when complete instead of as they arrive")
+	printCmd("--proxy URL", "Send requests through a proxy (also KEKE_PROXY)")
+	printCmd("--provider P", "AI provider for this run: anthropic, groq, openrouter or openai (default: default_provider)")
+	printCmd("--http-timeout N", "Seconds to wait for the server (default 30, 120 for AI calls)")
+	printCmd("--sandbox", "Run AI commands in Docker (--sandbox-required: never outside)")
+	printCmd("--yes", "Answer yes to confirmations (also KEKE_ASSUME_YES)")
+	printCmd("--force", "Overwrite files; with --yes also run dangerous commands")
+	printCmd("-q, -v", "Quiet (warnings, errors, results) or verbose (HTTP requests); also KEKE_LOG_LEVEL")
+	fmt.Println()
+
+	fmt.Println("  EXIT CODES")
+	fmt.Println()
+	fmt.Println("    0 success  1 error  2 not logged in  3 not initialized  4 no credits  5 network error")
+	fmt.Println()
+
 	printDivider()
 	logInfo("Software:    keke ask \"add login feature\"")
 	logInfo("Research:    keke research \"analyze this dataset\"")
@@ -119,5 +409,5 @@ func printCmd(name, desc string) {
 	for i := 0; i < padding; i++ {
 		spaces += " "
 	}
-	fmt.Printf("    \033[36m%s\033[0m%s\033[2m%s\033[0m\n", name, spaces, desc)
-}
\ No newline at end of file
+	fmt.Printf("    %s%s%s%s%s%s%s\n", cyan, name, reset, spaces, dim, desc, reset)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+// runJSON runs fn in --json mode and parses what flushJSON prints
+func runJSON(t *testing.T, fn func()) []jsonEntry {
+	t.Helper()
+	jsonMode = true
+	defer func() { jsonMode = false }()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	fn()
+	flushJSON()
+	os.Stdout = stdout
+	w.Close()
+
+	out, _ := io.ReadAll(r)
+	var entries []jsonEntry
+	if err := json.Unmarshal(out, &entries); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	return entries
+}
+
+// resultOf returns the data of the single result entry, re-decoded into v
+func resultOf(t *testing.T, entries []jsonEntry, v interface{}) {
+	t.Helper()
+	var results []interface{}
+	for _, entry := range entries {
+		if entry.Level == "result" {
+			results = append(results, entry.Data)
+		}
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1: %+v", len(results), entries)
+	}
+	data, _ := json.Marshal(results[0])
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("unexpected result %s: %v", data, err)
+	}
+}
+
+func TestJSONVersion(t *testing.T) {
+	var result struct {
+		Version string `json:"version"`
+	}
+	resultOf(t, runJSON(t, handleVersion), &result)
+	if result.Version != version {
+		t.Errorf("version = %q, want %q", result.Version, version)
+	}
+}
+
+func TestJSONSessionsList(t *testing.T) {
+	newTestProject(t)
+	session := newSession("ask", "test-model")
+	session.LastPrompt = "add a login page"
+	session.CreditsUsed = 3
+	if err := saveSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	var result []struct {
+		ID          string `json:"id"`
+		Mode        string `json:"mode"`
+		Last        string `json:"last"`
+		CreditsUsed int    `json:"credits_used"`
+	}
+	resultOf(t, runJSON(t, func() { handleSessions([]string{"list"}) }), &result)
+	if len(result) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(result))
+	}
+	if got := result[0]; got.ID != session.ID || got.Mode != "ask" || got.Last != "add a login page" || got.CreditsUsed != 3 {
+		t.Errorf("session = %+v", got)
+	}
+}
+
+func TestJSONSnapshotListings(t *testing.T) {
+	newTestProject(t)
+	writeTestFile(t, "src/auth.go", "package src")
+	if err := createSnapshot("src/auth.go"); err != nil {
+		t.Fatal(err)
+	}
+	handleSnapshotSave([]string{"before-auth", "src/auth.go"})
+
+	var snapshots []SnapshotInfo
+	resultOf(t, runJSON(t, func() { handleSnapshots(nil) }), &snapshots)
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snapshots))
+	}
+	for _, snap := range snapshots {
+		if snap.OriginalFile != "src/auth.go" {
+			t.Errorf("snapshot of %q, want src/auth.go", snap.OriginalFile)
+		}
+	}
+
+	var named []struct {
+		Name      string         `json:"name"`
+		Snapshots []SnapshotInfo `json:"snapshots"`
+	}
+	resultOf(t, runJSON(t, handleSnapshotList), &named)
+	if len(named) != 1 || named[0].Name != "before-auth" || len(named[0].Snapshots) != 1 {
+		t.Errorf("named snapshots = %+v", named)
+	}
+}
+
+func TestJSONPartialOutput(t *testing.T) {
+	entries := runJSON(t, func() { printPartialOutput([]byte("line 1\nline 2\n")) })
+	for _, entry := range entries {
+		if entry.Level == "message" && entry.Message == "line 1\nline 2" {
+			return
+		}
+	}
+	t.Errorf("partial output missing from %+v", entries)
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ─── MIGRATE ─────────────────────────────────────────────────────────────────
+// 'keke migrate --from python@2 --to python@3' ports the project one file at
+// a time (to stay within context limits). Each file goes through write_file,
+// so it is previewed and snapshotted, and migration-report.md lists what
+// changed and what needs a human
+
+const migrationReportFile = "migration-report.md"
+
+// MigrationResult - the AI's port of one file
+type MigrationResult struct {
+	Content   string   `json:"content"`   // the complete migrated file
+	Changes   []string `json:"changes"`   // what was changed
+	Unhandled []string `json:"unhandled"` // patterns left for a human
+}
+
+// Sent with each file so the reply is a single MigrationResult JSON object
+const migrateInstruction = `Migrate this file from %s to %s. Keep behavior the same and change only what the migration requires. Reply with ONLY a JSON object, no prose and no code fences, in this shape:
+{"content": "<the complete migrated file>", "changes": ["..."], "unhandled": ["<patterns you could not migrate safely, with line numbers>"]}`
+
+// File extensions per language, for --from
+var migrateExtensions = map[string][]string{
+	"python":     {".py"},
+	"javascript": {".js", ".jsx", ".mjs"},
+	"react":      {".js", ".jsx", ".ts", ".tsx"},
+	"typescript": {".ts", ".tsx"},
+	"go":         {".go"},
+	"java":       {".java"},
+	"ruby":       {".rb"},
+	"php":        {".php"},
+	"rust":       {".rs"},
+}
+
+// migratedFile - one line of the migration report
+type migratedFile struct {
+	Path   string
+	Status string // migrated, unchanged, skipped, failed
+	MigrationResult
+}
+
+func handleMigrate(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	from, to, dir := "", "", "."
+	model := getConfig().DefaultModel
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--from", "--to":
+			if i+1 >= len(args) {
+				logError(fmt.Sprintf("%s needs a language, e.g. python@3", args[i]))
+				return
+			}
+			if args[i] == "--from" {
+				from = strings.ToLower(args[i+1])
+			} else {
+				to = strings.ToLower(args[i+1])
+			}
+			i++
+		case "--fast", "--smart", "--deep":
+			model = strings.TrimPrefix(args[i], "--")
+		default:
+			dir = args[i]
+		}
+	}
+
+	if from == "" || to == "" {
+		logError("Usage: keke migrate --from <lang@version> --to <lang@version> [dir]")
+		logInfo("Examples:")
+		logInfo("  keke migrate --from python@2 --to python@3")
+		logInfo("  keke migrate --from react@class --to react@hooks src/")
+		return
+	}
+
+	lang := strings.SplitN(from, "@", 2)[0]
+	extensions, ok := migrateExtensions[lang]
+	if !ok {
+		var known []string
+		for name := range migrateExtensions {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		logError(fmt.Sprintf("Unsupported language %s (supported: %s)", lang, strings.Join(known, ", ")))
+		return
+	}
+
+	// Enumerate through the list_files action so the read permission applies
+	listing := handleListFiles(Action{Type: "list_files", Path: dir})
+	if listing == "Permission denied by user" || strings.HasPrefix(listing, "Error listing files") {
+		logError(listing)
+		return
+	}
+	var files []string
+	for _, path := range strings.Split(listing, "\n") {
+		for _, ext := range extensions {
+			if path != "" && strings.EqualFold(filepath.Ext(path), ext) {
+				files = append(files, path)
+			}
+		}
+	}
+	if len(files) == 0 {
+		logInfo(fmt.Sprintf("No %s files found in %s", lang, dir))
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("Migrating %d files from %s to %s", len(files), from, to))
+
+	var report []migratedFile
+	credits := 0
+	for i, path := range files {
+		printDivider()
+		logInfo(fmt.Sprintf("[%d/%d] %s", i+1, len(files), path))
+		file, used := migrateFile(path, from, to, model, auth)
+		credits += used
+		report = append(report, file)
+	}
+
+	printDivider()
+	if dryRun {
+		logInfo(fmt.Sprintf("[DRY RUN] Would write %s", migrationReportFile))
+	} else if err := os.WriteFile(migrationReportFile, []byte(migrationReport(from, to, report)), 0644); err != nil {
+		logError(fmt.Sprintf("Failed to write %s: %v", migrationReportFile, err))
+	} else {
+		logSuccess(fmt.Sprintf("Report: %s", migrationReportFile))
+	}
+	logInfo(fmt.Sprintf("Credits used: %d", credits))
+}
+
+// migrateFile sends one file to the AI and writes back the migrated version
+func migrateFile(path, from, to, model string, auth *AuthData) (migratedFile, int) {
+	file := migratedFile{Path: path, Status: "failed"}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", path, err))
+		return file, 0
+	}
+	if isBinary(content) || len(content) > maxToolOutputBytes {
+		logWarning(fmt.Sprintf("Skipped %s (binary or larger than %s)", path, formatBytes(maxToolOutputBytes)))
+		file.Status = "skipped"
+		return file, 0
+	}
+
+	payload := map[string]interface{}{
+		"conversation": []map[string]string{
+			{"role": "user", "content": fmt.Sprintf(migrateInstruction, from, to) + "\n\nFile: " + path + "\n" + string(content)},
+		},
+		"model": model,
+		"mode":  "migrate", // One file in, one file out, no actions
+	}
+
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		logRequestError("AI error", err)
+		return file, 0
+	}
+	if err := decodeJSONReply(response.Message, &file.MigrationResult); err != nil || file.Content == "" {
+		logError(fmt.Sprintf("Invalid migration for %s", path))
+		return file, response.CreditsUsed
+	}
+
+	if file.Content == string(content) {
+		logInfo("No changes needed")
+		file.Status = "unchanged"
+		return file, response.CreditsUsed
+	}
+
+	result := handleWriteFile(Action{Type: "write_file", Path: path, Content: file.Content})
+	if strings.HasPrefix(result, "Successfully wrote") {
+		file.Status = "migrated"
+	} else {
+		logWarning(result)
+		file.Status = "skipped"
+	}
+	for _, note := range file.Unhandled {
+		logWarning(fmt.Sprintf("Needs review: %s", note))
+	}
+	return file, response.CreditsUsed
+}
+
+// migrationReport renders the report as Markdown
+func migrationReport(from, to string, files []migratedFile) string {
+	counts := make(map[string]int)
+	for _, file := range files {
+		counts[file.Status]++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Migration report: %s → %s\n\n", from, to)
+	fmt.Fprintf(&b, "Generated by keke on %s.\n\n", time.Now().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "%d migrated, %d unchanged, %d skipped, %d failed.\n",
+		counts["migrated"], counts["unchanged"], counts["skipped"], counts["failed"])
+
+	for _, file := range files {
+		fmt.Fprintf(&b, "\n## %s (%s)\n", file.Path, file.Status)
+		if len(file.Changes) > 0 {
+			b.WriteString("\nChanged:\n")
+			for _, change := range file.Changes {
+				fmt.Fprintf(&b, "- %s\n", change)
+			}
+		}
+		if len(file.Unhandled) > 0 {
+			b.WriteString("\nNeeds manual review:\n")
+			for _, note := range file.Unhandled {
+				fmt.Fprintf(&b, "- %s\n", note)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ─── NOTEBOOK EXPORT ─────────────────────────────────────────────────────────
+// 'keke research --output analysis.ipynb' saves the conversation as a Jupyter
+// notebook (nbformat 4.5): prompts and AI prose become Markdown cells, code
+// blocks in AI replies become code cells, and action results become the
+// output of the code cell before them
+
+// notebookCell - one cell; see MarshalJSON for how it is written
+type notebookCell struct {
+	ID       string
+	CellType string // markdown or code
+	Source   []string
+	Outputs  []notebookOutput
+}
+
+type notebookOutput struct {
+	OutputType string   `json:"output_type"`
+	Name       string   `json:"name"`
+	Text       []string `json:"text"`
+}
+
+// buildNotebook turns a conversation into notebook JSON, in message order
+func buildNotebook(conversationHistory []map[string]string) []byte {
+	var cells []*notebookCell
+	addCell := func(cellType, source string) *notebookCell {
+		cell := &notebookCell{
+			ID:       fmt.Sprintf("cell-%d", len(cells)+1),
+			CellType: cellType,
+			Source:   notebookLines(source),
+		}
+		cells = append(cells, cell)
+		return cell
+	}
+
+	var lastCode *notebookCell
+	for _, message := range conversationHistory {
+		content := message["content"]
+		switch {
+		case message["role"] == "user" && strings.HasPrefix(content, "Action result: "):
+			// Output of the code just suggested, or of an action of its own
+			if lastCode == nil || len(lastCode.Outputs) > 0 {
+				lastCode = addCell("code", "# Action run by Keke")
+			}
+			lastCode.Outputs = append(lastCode.Outputs, notebookOutput{
+				OutputType: "stream",
+				Name:       "stdout",
+				Text:       notebookLines(strings.TrimPrefix(content, "Action result: ")),
+			})
+
+		case message["role"] == "user":
+			addCell("markdown", "**Prompt:** "+content)
+			lastCode = nil
+
+		default:
+			lastCode = nil
+			for _, block := range splitCodeBlocks(content) {
+				if block.Code {
+					lastCode = addCell("code", notebookCode(block.Language, block.Text))
+				} else if strings.TrimSpace(block.Text) != "" {
+					addCell("markdown", strings.TrimSpace(block.Text))
+				}
+			}
+		}
+	}
+
+	notebook := map[string]interface{}{
+		"nbformat":       4,
+		"nbformat_minor": 5,
+		"metadata": map[string]interface{}{
+			"kernelspec": map[string]string{
+				"name":         "python3",
+				"display_name": "Python 3",
+				"language":     "python",
+			},
+			"language_info": map[string]string{"name": "python"},
+		},
+		"cells": cells,
+	}
+	data, _ := json.MarshalIndent(notebook, "", " ")
+	return append(data, '\n')
+}
+
+// MarshalJSON gives code cells the execution_count and outputs fields the
+// schema requires of them, and leaves both out of Markdown cells
+func (c *notebookCell) MarshalJSON() ([]byte, error) {
+	fields := map[string]interface{}{
+		"id":        c.ID,
+		"cell_type": c.CellType,
+		"metadata":  map[string]interface{}{},
+		"source":    c.Source,
+	}
+	if c.CellType == "code" {
+		outputs := c.Outputs
+		if outputs == nil {
+			outputs = []notebookOutput{}
+		}
+		fields["execution_count"] = nil
+		fields["outputs"] = outputs
+	}
+	return json.Marshal(fields)
+}
+
+// codeBlock - a stretch of an AI reply, either prose or a fenced code block
+type codeBlock struct {
+	Code     bool
+	Language string
+	Text     string
+}
+
+// splitCodeBlocks cuts a Markdown reply at ``` fences
+func splitCodeBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	var current []string
+	inCode, language := false, ""
+
+	flush := func() {
+		blocks = append(blocks, codeBlock{Code: inCode, Language: language, Text: strings.Join(current, "\n")})
+		current = nil
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			current = append(current, line)
+			continue
+		}
+		flush()
+		if inCode {
+			inCode, language = false, ""
+		} else {
+			inCode, language = true, strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+		}
+	}
+	flush()
+	return blocks
+}
+
+// notebookCode prepares code for the Python kernel: shell snippets run
+// through the %%bash cell magic. Unlabelled blocks are guessed from content
+func notebookCode(language, code string) string {
+	if language == "" {
+		language = guessCodeLanguage(code)
+	}
+	switch language {
+	case "bash", "sh", "shell", "console", "zsh":
+		lines := strings.Split(code, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimPrefix(line, "$ ")
+		}
+		return "%%bash\n" + strings.Join(lines, "\n")
+	}
+	return code
+}
+
+// guessCodeLanguage tells shell commands from Python by their first line
+func guessCodeLanguage(code string) string {
+	first := strings.TrimSpace(strings.SplitN(strings.TrimSpace(code), "\n", 2)[0])
+	if strings.HasPrefix(first, "$ ") || strings.HasPrefix(first, "#!/bin/") {
+		return "bash"
+	}
+	if first == "ls" {
+		return "bash"
+	}
+	for _, command := range []string{"pip ", "cd ", "ls ", "python ", "python3 ", "mkdir ", "export ", "echo ", "curl ", "wget ", "git ", "conda "} {
+		if strings.HasPrefix(first, command) {
+			return "bash"
+		}
+	}
+	return "python"
+}
+
+// notebookLines splits text into the line list notebooks store, each line
+// but the last keeping its newline
+func notebookLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+)
+
+// ─── PERMISSIONS ─────────────────────────────────────────────────────────────
+// Review and revoke the allow-list saved in .keke/permissions.json
+
+func handlePermissions(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		listPermissions()
+		return
+	}
+
+	switch args[0] {
+	case "reset":
+		resetPermissions()
+	case "revoke":
+		revokePermission(args[1:])
+	default:
+		logError(fmt.Sprintf("Unknown subcommand: %s", args[0]))
+		printPermissionsUsage()
+	}
+}
+
+func printPermissionsUsage() {
+	logInfo("Usage:")
+	logInfo("  keke permissions list")
+	logInfo("  keke permissions revoke <read|write|execute> [pattern]")
+	logInfo("  keke permissions reset")
+}
+
+// resetPermissions drops every grant so the AI has to ask again
+func resetPermissions() {
+	perms := &Permissions{Read: []string{}, Write: []string{}, Execute: []string{}}
+	if err := writePermissions(perms); err != nil {
+		logError(fmt.Sprintf("Failed to save permissions: %v", err))
+		return
+	}
+	logSuccess("All permissions revoked")
+}
+
+// revokePermission removes one pattern, or every grant of a type
+func revokePermission(args []string) {
+	if len(args) == 0 {
+		printPermissionsUsage()
+		return
+	}
+
+	permType := args[0]
+	if permType != "read" && permType != "write" && permType != "execute" {
+		logError(fmt.Sprintf("Unknown permission type: %s (use read, write or execute)", permType))
+		return
+	}
+
+	perms, _ := readPermissions()
+	var kept []string
+	if len(args) > 1 {
+		pattern := args[1]
+		for _, existing := range perms.grants(permType) {
+			if existing != pattern {
+				kept = append(kept, existing)
+			}
+		}
+		if len(kept) == len(perms.grants(permType)) {
+			logError(fmt.Sprintf("No %s grant for %s", permType, pattern))
+			return
+		}
+	}
+	if kept == nil {
+		kept = []string{}
+	}
+
+	switch permType {
+	case "read":
+		perms.Read = kept
+	case "write":
+		perms.Write = kept
+	case "execute":
+		perms.Execute = kept
+	}
+
+	if err := writePermissions(perms); err != nil {
+		logError(fmt.Sprintf("Failed to save permissions: %v", err))
+		return
+	}
+
+	if len(args) > 1 {
+		logSuccess(fmt.Sprintf("Revoked %s %s", permType, args[1]))
+	} else {
+		logSuccess(fmt.Sprintf("Revoked all %s permissions", permType))
+	}
+}
+
+func listPermissions() {
+	perms, _ := readPermissions()
+
+	printDivider()
+	for _, permType := range []string{"read", "write", "execute"} {
+		grants := perms.grants(permType)
+		fmt.Printf("%s%s%s %s(%d)%s\n", bold, permType, reset, dim, len(grants), reset)
+		if len(grants) == 0 {
+			fmt.Printf("  %snothing granted%s\n", dim, reset)
+		}
+		for _, pattern := range grants {
+			fmt.Printf("  %s•%s %s\n", cyan, reset, pattern)
+		}
+	}
+	printDivider()
+	logInfo(fmt.Sprintf("Stored in %s", projectPermissionsFile()))
+}
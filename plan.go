@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ─── PLAN ────────────────────────────────────────────────────────────────────
+// 'keke plan' asks the AI for an implementation plan only. Nothing is read,
+// written or run; the plan is saved so 'keke ask --use-plan' can follow it
+
+// ExecutionPlan - the steps the AI intends to take for a task
+type ExecutionPlan struct {
+	Prompt    string     `json:"prompt"`
+	Summary   string     `json:"summary"`
+	Steps     []PlanStep `json:"steps"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type PlanStep struct {
+	Description string   `json:"description"`
+	Files       []string `json:"files,omitempty"`    // files it will create or change
+	Commands    []string `json:"commands,omitempty"` // commands it will run
+}
+
+// Sent with the task so the reply is a single ExecutionPlan JSON object
+const planInstruction = `Do not perform any actions. Reply with ONLY a JSON object, no prose and no code fences, in this shape:
+{"summary": "...", "steps": [{"description": "...", "files": ["..."], "commands": ["..."]}]}`
+
+func handlePlan(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	if len(args) == 0 {
+		logError("Usage: keke plan \"your task\"")
+		logInfo("Then run: keke ask --use-plan \"go ahead\"")
+		return
+	}
+
+	opts, err := parseAskFlags(args)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	if opts.Interactive {
+		logError("--interactive is only available for 'keke ask' (or use 'keke repl')")
+		return
+	}
+	if opts.NoProject {
+		logError("--no-project is only available for 'keke ask'")
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo("AI planning...")
+
+	plan, credits, err := callPlanAI(opts.Prompt, opts.Model, auth)
+	if err != nil {
+		logRequestError("AI error", err)
+		return
+	}
+
+	if err := savePlan(plan); err != nil {
+		logWarning(fmt.Sprintf("Failed to save plan: %v", err))
+	}
+
+	if jsonMode {
+		emitJSON(plan)
+		return
+	}
+
+	displayPlanCompact(plan)
+	printDivider()
+	logInfo(fmt.Sprintf("Credits used: %d", credits))
+	logInfo("Run it with: keke ask --use-plan \"go ahead\"")
+}
+
+// callPlanAI requests a plan for prompt and decodes it from the reply
+func callPlanAI(prompt, model string, auth *AuthData) (*ExecutionPlan, int, error) {
+	payload := map[string]interface{}{
+		"conversation": []map[string]string{
+			{"role": "user", "content": planInstruction + "\n\nTask: " + prompt},
+		},
+		"model": model,
+		"mode":  "plan", // Plan only, no actions
+	}
+
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var plan ExecutionPlan
+	if err := decodeJSONReply(response.Message, &plan); err != nil {
+		return nil, response.CreditsUsed, fmt.Errorf("invalid plan: %v", err)
+	}
+	if len(plan.Steps) == 0 {
+		return nil, response.CreditsUsed, fmt.Errorf("AI returned an empty plan")
+	}
+
+	plan.Prompt = prompt
+	plan.CreatedAt = time.Now()
+	return &plan, response.CreditsUsed, nil
+}
+
+func savePlan(plan *ExecutionPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(projectPlanFile(), data, 0644)
+}
+
+func loadPlan() (*ExecutionPlan, error) {
+	data, err := os.ReadFile(projectPlanFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no saved plan, run 'keke plan' first")
+		}
+		return nil, err
+	}
+	var plan ExecutionPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("corrupt plan file: %v", err)
+	}
+	return &plan, nil
+}
+
+// planContext is the conversation message that hands a saved plan to the AI
+func planContext(plan *ExecutionPlan) string {
+	data, _ := json.MarshalIndent(plan, "", "  ")
+	return "Follow this implementation plan instead of making a new one:\n" + string(data)
+}
+
+// displayPlanCompact prints a plan as a numbered list of steps
+func displayPlanCompact(plan *ExecutionPlan) {
+	printDivider()
+	if plan.Summary != "" {
+		fmt.Printf("%s%s%s\n\n", bold, plan.Summary, reset)
+	}
+	for i, step := range plan.Steps {
+		fmt.Printf("%s%2d.%s %s\n", cyan, i+1, reset, step.Description)
+		for _, file := range step.Files {
+			fmt.Printf("     %sfile:%s %s\n", dim, reset, file)
+		}
+		for _, command := range step.Commands {
+			fmt.Printf("     %srun:%s  %s\n", dim, reset, command)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// ─── REPL ────────────────────────────────────────────────────────────────────
+// 'keke repl' (or 'keke ask --interactive') keeps one ask session open:
+// every line typed is a follow-up prompt. /model switches model, /clear
+// starts a fresh session, exit, /exit or Ctrl+D quits
+
+// handleRepl is 'keke ask --interactive': it takes the same flags, parsed by
+// parseAskFlags, and a prompt, if given, is answered before the first read
+func handleRepl(args []string) {
+	handleAsk(append(args, "--interactive"))
+}
+
+// runRepl reads prompts until exit or end of input, sending each to the AI
+// in session and reporting the credits every turn used
+func runRepl(session *SessionData, model string, auth *AuthData) {
+	logInfo(fmt.Sprintf("Keke REPL (%s). Type /help for commands, exit to quit", model))
+	for {
+		fmt.Fprintf(promptOutput(), "\n%s%skeke>%s ", bold, magenta, reset)
+		line, err := readLine()
+		if err != nil {
+			fmt.Fprintln(promptOutput())
+			closeRepl(session)
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue // nothing to send, so no credits spent
+		}
+		if !strings.HasPrefix(line, "/") && line != "exit" && line != "quit" {
+			before := session.CreditsUsed
+			conversationLoop(session, line, model, auth)
+			logInfo(fmt.Sprintf("Credits: %d this turn, %d this session", session.CreditsUsed-before, session.CreditsUsed))
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch strings.TrimPrefix(fields[0], "/") {
+		case "exit", "quit":
+			closeRepl(session)
+			return
+		case "model":
+			if len(fields) != 2 || (fields[1] != "fast" && fields[1] != "smart" && fields[1] != "deep") {
+				logWarning("Usage: /model fast|smart|deep")
+				continue
+			}
+			model = fields[1]
+			logSuccess(fmt.Sprintf("Model set to %s", model))
+		case "clear":
+			session = newSession("ask", model)
+			logSuccess("Conversation cleared")
+		case "help":
+			logInfo("/model fast|smart|deep  Switch model")
+			logInfo("/clear                  Start a new conversation")
+			logInfo("exit, /exit or Ctrl+D   Save the session and quit")
+		default:
+			logWarning(fmt.Sprintf("Unknown command: %s (try /help)", fields[0]))
+		}
+	}
+}
+
+// closeRepl saves session on the way out and says how to resume it
+func closeRepl(session *SessionData) {
+	if len(session.History) == 0 {
+		return
+	}
+	if err := saveSession(session); err != nil {
+		logWarning(fmt.Sprintf("Failed to save session: %v", err))
+	}
+	logInfo(fmt.Sprintf("Resume with: keke repl --session %s", session.ID))
+}
+
+// readLine reads one line from stdin a byte at a time, so nothing is
+// buffered away from the permission prompts that also read stdin. The
+// terminal edits the line before sending it; backspaces that still arrive
+// (raw terminals, pasted input) remove the character before them
+func readLine() (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 1 {
+			switch b[0] {
+			case '\n':
+				return strings.TrimSuffix(string(line), "\r"), nil
+			case '\b', 0x7f:
+				line = dropLastRune(line)
+			default:
+				line = append(line, b[0])
+			}
+		}
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				return string(line), nil
+			}
+			return "", err
+		}
+	}
+}
+
+func dropLastRune(b []byte) []byte {
+	if len(b) == 0 {
+		return b
+	}
+	_, size := utf8.DecodeLastRune(b)
+	return b[:len(b)-size]
+}
@@ -1,10 +1,9 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -21,11 +20,18 @@ import (
 func handleResearch(args []string) {
 	if !isLoggedIn() {
 		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
 		return
 	}
 
 	if !isProjectInitialized() {
 		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	if len(args) > 0 && args[0] == "compare" {
+		handleResearchCompare(args[1:])
 		return
 	}
 
@@ -36,29 +42,43 @@ func handleResearch(args []string) {
 		logInfo("  keke research \"design experiment to compare models\"")
 		logInfo("  keke research \"validate my CNN architecture\"")
 		logInfo("  keke research \"explain why my model is overfitting\"")
+		logInfo("  keke research --output analysis.ipynb \"explore data.csv\"")
+		logInfo("  keke research --dataset sales.csv \"what drives revenue?\"")
+		logInfo("  keke research compare random_forest xgboost --dataset churn.csv")
 		return
 	}
 
-	// Parse flags
-	model := "smart" // default
-	var promptParts []string
-
-	for _, arg := range args {
-		switch arg {
-		case "--fast":
-			model = "fast"
-		case "--smart":
-			model = "smart"
-		case "--deep":
-			model = "deep"
-		default:
-			promptParts = append(promptParts, arg)
+	// --output and --dataset are research-only; everything else is shared
+	// with 'keke ask'
+	output, datasetPath := "", ""
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--output" && i+1 < len(args) {
+			output = args[i+1]
+			i++
+		} else if args[i] == "--dataset" && i+1 < len(args) {
+			datasetPath = args[i+1]
+			i++
+		} else {
+			rest = append(rest, args[i])
 		}
 	}
+	if output != "" && !strings.HasSuffix(output, ".ipynb") {
+		logError(fmt.Sprintf("--output %s: only .ipynb notebooks are supported", output))
+		return
+	}
 
-	prompt := strings.Join(promptParts, " ")
-	if prompt == "" {
-		logError("No prompt provided")
+	opts, err := parseAskFlags(rest)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	if opts.Interactive {
+		logError("--interactive is only available for 'keke ask' (or use 'keke repl')")
+		return
+	}
+	if opts.NoProject {
+		logError("--no-project is only available for 'keke ask'")
 		return
 	}
 
@@ -68,34 +88,121 @@ func handleResearch(args []string) {
 		return
 	}
 
-	logInfo("AI analyzing your research request...")
+	logInfo(fmt.Sprintf("AI analyzing your research request (%s)...", modelLabel(opts.Model)))
 
 	// Start research conversation loop
-	researchLoop(prompt, model, auth)
+	session, err := openSession("research", opts)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	if datasetPath != "" {
+		message, err := preloadDataset(datasetPath)
+		if err != nil {
+			logError(err.Error())
+			return
+		}
+		session.History = append(session.History, map[string]string{
+			"role":    "user",
+			"content": message,
+		})
+	}
+
+	if err := attachFiles(session, opts.Files, opts.Model, auth); err != nil {
+		logError(err.Error())
+		return
+	}
+
+	researchLoop(session, opts.Prompt, opts.Model, auth)
+
+	if output != "" {
+		writeNotebook(output, session.History)
+	}
+}
+
+// Summaries of the datasets loaded this run, by cleaned path, so load_dataset
+// on a --dataset file answers without reading it again
+var loadedDatasets = map[string]string{}
+
+// preloadDataset reads the --dataset file and returns the message that puts
+// it in the conversation: its summary, plus the raw content when it is no
+// larger than maxInlineDatasetBytes
+func preloadDataset(path string) (string, error) {
+	if refusal := guardRead(path, fmt.Sprintf("Preload dataset: %s", path)); refusal != "" {
+		return "", fmt.Errorf("Cannot preload %s: %s", path, refusal)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("Cannot read dataset: %v", err)
+	}
+	dataset, err := loadDataset(path)
+	if err != nil {
+		return "", fmt.Errorf("Cannot load dataset %s: %v", path, err)
+	}
+
+	summary := summarizeDataset(dataset, info.Size())
+	loadedDatasets[filepath.Clean(path)] = summary
+	logInfo(fmt.Sprintf("Preloaded %s: %d rows x %d columns (%s)", path, len(dataset.Rows), len(dataset.Columns), dataset.Format))
+
+	message := "The user preloaded this dataset (load_dataset is not needed for it):\n" + summary
+	if info.Size() <= maxInlineDatasetBytes {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("Cannot read dataset: %v", err)
+		}
+		message += fmt.Sprintf("\n\nFull content of %s:\n%s", path, content)
+	} else {
+		logInfo(fmt.Sprintf("%s is larger than %s: sending its summary only", path, formatBytes(maxInlineDatasetBytes)))
+	}
+	return message, nil
+}
+
+// writeNotebook saves the research conversation as a Jupyter notebook
+func writeNotebook(path string, history []map[string]string) {
+	if dryRun {
+		logInfo(fmt.Sprintf("[DRY RUN] Would write notebook %s", path))
+		return
+	}
+	if err := os.WriteFile(path, buildNotebook(history), 0644); err != nil {
+		logError(fmt.Sprintf("Failed to write notebook: %v", err))
+		return
+	}
+	logSuccess(fmt.Sprintf("Notebook saved: %s (open with: jupyter lab %s)", path, path))
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
 // RESEARCH CONVERSATION LOOP
 // ═══════════════════════════════════════════════════════════════════════════
 
-func researchLoop(initialPrompt, model string, auth *AuthData) {
-	var conversationHistory []map[string]string
+func researchLoop(session *SessionData, initialPrompt, model string, auth *AuthData) {
+	conversationHistory := session.History
 
 	conversationHistory = append(conversationHistory, map[string]string{
 		"role":    "user",
 		"content": initialPrompt,
 	})
 
-	maxIterations := 20
+	session.Model = model
+	session.LastPrompt = initialPrompt
+	auditSessionID = session.ID
+
+	limit := iterationLimit()
+	maxIterations := limit
 	iteration := 0
 
+	budget := sessionBudget()
+	spent, allowed := 0, budget
+	warnBudgetAboveBalance(budget, auth)
+
 	for iteration < maxIterations {
 		iteration++
 
 		// Call AI in research mode
 		response, err := callResearchAI(conversationHistory, model, auth)
 		if err != nil {
-			logError(fmt.Sprintf("AI error: %v", err))
+			logRequestError("AI error", err)
 			return
 		}
 
@@ -105,9 +212,19 @@ func researchLoop(initialPrompt, model string, auth *AuthData) {
 			"content": response.Message,
 		})
 
+		// Persist progress so 'keke status' can see the session
+		session.History = conversationHistory
+		session.CreditsUsed += response.CreditsUsed
+		spent += response.CreditsUsed
+		if err := saveSession(session); err != nil {
+			logWarning(fmt.Sprintf("Failed to save session: %v", err))
+		}
+
 		// Check if AI is done
 		if len(response.Actions) == 0 {
-			fmt.Println(response.Message)
+			if !response.streamed {
+				printMessage(response.Message)
+			}
 			printDivider()
 			logInfo(fmt.Sprintf("Total credits used: %d", response.CreditsUsed))
 			return
@@ -115,16 +232,27 @@ func researchLoop(initialPrompt, model string, auth *AuthData) {
 
 		// Execute research actions
 		for _, action := range response.Actions {
-			result := executeResearchAction(action)
+			result := truncateToolOutput(executeResearchAction(action))
+			session.recordAction(action, result)
 
 			conversationHistory = append(conversationHistory, map[string]string{
 				"role":    "user",
 				"content": fmt.Sprintf("Action result: %s", result),
 			})
 		}
+
+		session.History = conversationHistory
+		if overBudget(spent, response.CreditsUsed, &allowed, budget) {
+			saveSession(session)
+			return
+		}
+
+		if iteration == maxIterations && moreIterations(limit) {
+			maxIterations += limit
+		}
 	}
 
-	logWarning("Max iterations reached")
+	warnMaxIterations("research", session, maxIterations)
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -137,34 +265,7 @@ func callResearchAI(conversation []map[string]string, model string, auth *AuthDa
 		"model":        model,
 		"mode":         "research", // Research mode
 	}
-
-	jsonData, _ := json.Marshal(payload)
-	resp, err := makeAuthenticatedRequest(
-		"POST",
-		EndpointAI,
-		bytes.NewBuffer(jsonData),
-		auth,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 402 {
-		return nil, fmt.Errorf("insufficient credits")
-	}
-
-	if resp.StatusCode != 200 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server error: %s", string(body))
-	}
-
-	var response AIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
-	}
-
-	return &response, nil
+	return postAI(payload, auth, showStream())
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -197,45 +298,147 @@ func executeResearchAction(action Action) string {
 
 func handleLoadDataset(action Action) string {
 	path := action.Path
-	format := action.Format
 
-	if !checkPermission("read") {
-		if !requestPermission("read", fmt.Sprintf("AI wants to load dataset: %s", path)) {
-			return "Permission denied"
-		}
+	if summary, ok := loadedDatasets[filepath.Clean(path)]; ok {
+		logInfo(fmt.Sprintf("Dataset already loaded: %s", path))
+		return summary
 	}
 
-	logInfo(fmt.Sprintf("Loading dataset: %s (format: %s)", path, format))
-	
-	// In a real implementation, this would load and return dataset info
-	return fmt.Sprintf("Dataset loaded from %s. Format: %s. Shape: (1000, 10). Columns: [...]", path, format)
+	if refusal := guardRead(path, fmt.Sprintf("AI wants to load dataset: %s", path)); refusal != "" {
+		return refusal
+	}
+
+	logInfo(fmt.Sprintf("Loading dataset: %s", path))
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Sprintf("Error loading dataset: %v", err)
+	}
+	dataset, err := loadDataset(path)
+	if err != nil {
+		return fmt.Sprintf("Error loading dataset: %v", err)
+	}
+
+	summary := summarizeDataset(dataset, info.Size())
+	loadedDatasets[filepath.Clean(path)] = summary
+	return summary
 }
 
 func handleAnalyzeData(action Action) string {
 	analysisType := action.AnalysisType
-	
-	if !checkPermission("execute") {
-		if !requestPermission("execute", fmt.Sprintf("AI wants to run analysis: %s", analysisType)) {
-			return "Permission denied"
+	if analysisType == "" {
+		analysisType = "summary"
+	}
+
+	path := action.Path
+	if path == "" {
+		path = stringParam(action.Parameters, "path")
+	}
+	if path == "" {
+		return "Error: analyze_data needs a dataset path (path or parameters.path)"
+	}
+
+	if !ensurePermission("execute", "analyze:"+analysisType, fmt.Sprintf("AI wants to run analysis: %s", analysisType)) {
+		return "Permission denied by user"
+	}
+
+	if refusal := guardRead(path, fmt.Sprintf("AI wants to load dataset: %s", path)); refusal != "" {
+		return refusal
+	}
+
+	logInfo(fmt.Sprintf("Running analysis: %s on %s", analysisType, path))
+
+	dataset, err := loadDataset(path)
+	if err != nil {
+		return fmt.Sprintf("Error loading dataset: %v", err)
+	}
+
+	// Columns to analyze: parameters.column and/or parameters.columns,
+	// otherwise every numeric column
+	columns := stringListParam(action.Parameters, "columns")
+	if column := stringParam(action.Parameters, "column"); column != "" {
+		columns = append([]string{column}, columns...)
+	}
+	if len(columns) == 0 {
+		columns = dataset.numericColumns()
+		if len(columns) == 0 {
+			return fmt.Sprintf("Error: %s has no numeric columns (columns: %s)", path, strings.Join(dataset.Columns, ", "))
 		}
 	}
 
-	logInfo(fmt.Sprintf("Running analysis: %s", analysisType))
-	
-	// In real implementation, run statistical analysis
-	return fmt.Sprintf("Analysis '%s' complete. Mean: 42.5, Std: 12.3, Outliers: 15", analysisType)
+	switch analysisType {
+	case "summary":
+		return analyzeSummary(dataset, columns)
+	case "outliers":
+		return analyzeOutliers(dataset, columns)
+	case "correlation":
+		return analyzeCorrelation(dataset, columns)
+	default:
+		return fmt.Sprintf("Error: unknown analysis type '%s' (use summary, correlation or outliers)", analysisType)
+	}
+}
+
+func analyzeSummary(dataset *Dataset, columns []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Summary of %s (%d rows, %d columns)\n", dataset.Path, len(dataset.Rows), len(dataset.Columns))
+	for _, column := range columns {
+		values, err := dataset.numericColumn(column)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		s := describe(values)
+		fmt.Fprintf(&b, "%s: count=%d mean=%.4g std=%.4g min=%.4g q1=%.4g median=%.4g q3=%.4g max=%.4g outliers=%d\n",
+			column, s.Count, s.Mean, s.Std, s.Min, s.Q1, s.Median, s.Q3, s.Max, s.Outliers)
+	}
+	return b.String()
+}
+
+func analyzeOutliers(dataset *Dataset, columns []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "IQR outliers in %s (outside Q1-1.5*IQR .. Q3+1.5*IQR)\n", dataset.Path)
+	for _, column := range columns {
+		values, err := dataset.numericColumn(column)
+		if err != nil {
+			return fmt.Sprintf("Error: %v", err)
+		}
+		s := describe(values)
+		fmt.Fprintf(&b, "%s: %d of %d values outside [%.4g, %.4g]\n",
+			column, s.Outliers, s.Count, s.LowerFence, s.UpperFence)
+	}
+	return b.String()
+}
+
+func analyzeCorrelation(dataset *Dataset, columns []string) string {
+	if len(columns) < 2 {
+		return "Error: correlation needs at least two numeric columns"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Pearson correlation in %s\n", dataset.Path)
+	for i := 0; i < len(columns); i++ {
+		for j := i + 1; j < len(columns); j++ {
+			r, n, err := dataset.pearson(columns[i], columns[j])
+			if err != nil {
+				return fmt.Sprintf("Error: %v", err)
+			}
+			fmt.Fprintf(&b, "%s ~ %s: r=%.4f (n=%d)\n", columns[i], columns[j], r, n)
+		}
+	}
+	return b.String()
 }
 
 func handleTrainModel(action Action) string {
 	modelType := action.ModelType
 	
-	if !checkPermission("execute") {
-		if !requestPermission("execute", fmt.Sprintf("AI wants to train model: %s", modelType)) {
-			return "Permission denied"
-		}
+	if !ensurePermission("execute", "train:"+modelType, fmt.Sprintf("AI wants to train model: %s", modelType)) {
+		return "Permission denied by user"
 	}
 
-	logInfo(fmt.Sprintf("Training model: %s", modelType))
+	if dataset := stringParam(action.Parameters, "dataset"); dataset != "" {
+		logInfo(fmt.Sprintf("Training model: %s on %s", modelType, dataset))
+	} else {
+		logInfo(fmt.Sprintf("Training model: %s", modelType))
+	}
 	
 	// In real implementation, train model
 	return fmt.Sprintf("Model '%s' trained. Accuracy: 0.92, Loss: 0.15", modelType)
@@ -244,10 +447,8 @@ func handleTrainModel(action Action) string {
 func handleEvaluateModel(action Action) string {
 	modelPath := action.Path
 	
-	if !checkPermission("execute") {
-		if !requestPermission("execute", fmt.Sprintf("AI wants to evaluate model: %s", modelPath)) {
-			return "Permission denied"
-		}
+	if !ensurePermission("execute", "evaluate:"+modelPath, fmt.Sprintf("AI wants to evaluate model: %s", modelPath)) {
+		return "Permission denied by user"
 	}
 
 	logInfo(fmt.Sprintf("Evaluating model: %s", modelPath))
@@ -259,10 +460,8 @@ func handleEvaluateModel(action Action) string {
 func handleVisualize(action Action) string {
 	vizType := action.VizType
 	
-	if !checkPermission("write") {
-		if !requestPermission("write", fmt.Sprintf("AI wants to create visualization: %s", vizType)) {
-			return "Permission denied"
-		}
+	if !ensurePermission("write", "plots/", fmt.Sprintf("AI wants to create visualization: %s", vizType)) {
+		return "Permission denied by user"
 	}
 
 	logInfo(fmt.Sprintf("Creating visualization: %s", vizType))
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ─── REVIEW ──────────────────────────────────────────────────────────────────
+// 'keke review <file>' asks the AI for a code review and prints the issues as
+// file:line comments. Exits with 1 when a HIGH severity issue is found
+
+// ReviewResult - structured feedback returned in review mode
+type ReviewResult struct {
+	Summary     string        `json:"summary"`
+	Issues      []ReviewIssue `json:"issues"`
+	Suggestions []string      `json:"suggestions"`
+}
+
+type ReviewIssue struct {
+	Line     int    `json:"line"`
+	Severity string `json:"severity"` // HIGH, MEDIUM, LOW
+	Comment  string `json:"comment"`
+}
+
+// Sent with the file so the reply is a single ReviewResult JSON object
+const reviewInstruction = `Review this file. Lines are numbered as "N| code". Reply with ONLY a JSON object, no prose and no code fences, in this shape:
+{"summary": "...", "issues": [{"line": 42, "severity": "HIGH|MEDIUM|LOW", "comment": "..."}], "suggestions": ["..."]}`
+
+func handleReview(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	file := ""
+	model := getConfig().DefaultModel
+	for _, arg := range args {
+		switch arg {
+		case "--fast", "--smart", "--deep":
+			model = strings.TrimPrefix(arg, "--")
+		default:
+			file = arg
+		}
+	}
+
+	if file == "" {
+		logError("Usage: keke review <file> [--fast|--smart|--deep]")
+		return
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", file, err))
+		return
+	}
+	if isBinary(content) {
+		logError(fmt.Sprintf("%s is a binary file", file))
+		return
+	}
+	if len(content) > maxToolOutputBytes {
+		logError(fmt.Sprintf("%s is too large to review (%s, limit %s)",
+			file, formatBytes(int64(len(content))), formatBytes(maxToolOutputBytes)))
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("AI reviewing %s...", file))
+
+	result, credits, err := callReviewAI(file, content, model, auth)
+	if err != nil {
+		logRequestError("AI error", err)
+		return
+	}
+
+	high := false
+	for _, issue := range result.Issues {
+		if strings.EqualFold(issue.Severity, "HIGH") {
+			high = true
+		}
+	}
+	if high {
+		exitCode = exitError
+	}
+
+	if jsonMode {
+		emitJSON(result)
+		return
+	}
+
+	displayReview(file, result)
+	printDivider()
+	logInfo(fmt.Sprintf("Credits used: %d", credits))
+}
+
+// callReviewAI sends the file with numbered lines and decodes the review
+func callReviewAI(file string, content []byte, model string, auth *AuthData) (*ReviewResult, int, error) {
+	var numbered strings.Builder
+	for i, line := range splitLines(string(content)) {
+		fmt.Fprintf(&numbered, "%d| %s\n", i+1, line)
+	}
+
+	payload := map[string]interface{}{
+		"conversation": []map[string]string{
+			{"role": "user", "content": fmt.Sprintf("%s\n\nFile: %s\n%s", reviewInstruction, file, numbered.String())},
+		},
+		"model": model,
+		"mode":  "review", // Review only, no actions
+	}
+
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result ReviewResult
+	if err := decodeJSONReply(response.Message, &result); err != nil {
+		return nil, response.CreditsUsed, fmt.Errorf("invalid review: %v", err)
+	}
+	return &result, response.CreditsUsed, nil
+}
+
+// displayReview prints issues as "file:line: [SEVERITY] comment"
+func displayReview(file string, result *ReviewResult) {
+	printDivider()
+	if len(result.Issues) == 0 {
+		logSuccess("No issues found")
+	}
+	for _, issue := range result.Issues {
+		severity := strings.ToUpper(issue.Severity)
+		color := dim
+		switch severity {
+		case "HIGH":
+			color = red
+		case "MEDIUM":
+			color = yellow
+		}
+		fmt.Printf("%s:%d: %s[%s]%s %s\n", file, issue.Line, color, severity, reset, issue.Comment)
+	}
+
+	if result.Summary != "" {
+		fmt.Println()
+		fmt.Println(result.Summary)
+	}
+
+	if len(result.Suggestions) > 0 {
+		fmt.Println()
+		fmt.Printf("%s━━━ Suggestions ━━━%s\n", dim, reset)
+		for _, suggestion := range result.Suggestions {
+			fmt.Printf("  %s•%s %s\n", cyan, reset, suggestion)
+		}
+	}
+}
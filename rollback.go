@@ -2,11 +2,13 @@ package main
 
 import (
 	"fmt"
-	"io/ioutil"
-	// "os"
+	"os"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ─── ROLLBACK ────────────────────────────────────────────────────────────────
@@ -15,52 +17,123 @@ import (
 func handleRollback(args []string) {
 	if !isProjectInitialized() {
 		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
 		return
 	}
 
-	snapDir := projectSnapshotsDir()
+	// Parse flags
+	targetFile, filter, at, before := "", "", "", ""
+	all, preview, list, latest := false, false, false, false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--all":
+			all = true
+		case "--preview":
+			preview = true
+		case "--list":
+			list = true
+		case "--latest":
+			latest = true
+		case "--filter":
+			if i+1 >= len(args) {
+				logError("--filter needs a file name or pattern, e.g. '*.go'")
+				return
+			}
+			if _, err := path.Match(args[i+1], ""); err != nil {
+				logError(fmt.Sprintf("Invalid --filter pattern: %s", args[i+1]))
+				return
+			}
+			filter = args[i+1]
+			i++
+		case "--at", "--before":
+			if i+1 >= len(args) {
+				logError(fmt.Sprintf("%s needs a timestamp like 20060102_150405", args[i]))
+				return
+			}
+			if _, err := time.Parse(snapshotTimeFormat, args[i+1]); err != nil {
+				logError(fmt.Sprintf("Invalid timestamp %s (format: 20060102_150405)", args[i+1]))
+				return
+			}
+			if args[i] == "--at" {
+				at = args[i+1]
+			} else {
+				before = args[i+1]
+			}
+			i++
+		default:
+			targetFile = args[i]
+		}
+	}
 
-	// List all snapshots
-	files, err := ioutil.ReadDir(snapDir)
+	snapshots, err := loadSnapshots()
 	if err != nil {
 		logError("No snapshots found")
 		return
 	}
 
-	if len(files) == 0 {
+	if filter != "" {
+		snapshots = filterSnapshots(snapshots, filter)
+	}
+
+	if list {
+		listSnapshots(snapshots, targetFile)
+		return
+	}
+
+	if len(snapshots) == 0 {
 		logInfo("No snapshots available")
 		return
 	}
 
-	// Group snapshots by original file
-	snapshots := make(map[string][]SnapshotInfo)
-	for _, file := range files {
-		if !strings.HasSuffix(file.Name(), ".snap") {
-			continue
+	// --latest restores the newest snapshot of one file without asking
+	if latest {
+		if targetFile == "" {
+			logError("Usage: keke rollback <file> --latest")
+			return
+		}
+		snaps := snapshotsOf(snapshots, targetFile)
+		if len(snaps) == 0 {
+			logError(fmt.Sprintf("No snapshots found for: %s", targetFile))
+			return
+		}
+		if preview {
+			previewRestore(snaps[0])
 		}
+		if err := restoreSnapshot(snaps[0]); err != nil {
+			logError(err.Error())
+			return
+		}
+		logSuccess(fmt.Sprintf("Restored: %s", snaps[0].OriginalFile))
+		logInfo(fmt.Sprintf("From snapshot: %s", snaps[0].Timestamp))
+		return
+	}
 
-		// Parse: filename.timestamp.snap
-		parts := strings.Split(file.Name(), ".")
-		if len(parts) < 3 {
-			continue
+	if at != "" {
+		if targetFile == "" {
+			logError("Usage: keke rollback <file> --at 20060102_150405")
+			return
 		}
+		rollbackAt(snapshots, targetFile, at, preview)
+		return
+	}
 
-		originalFile := strings.Join(parts[:len(parts)-2], ".")
-		timestamp := parts[len(parts)-2]
+	if all && before == "" {
+		rollbackSession(snapshots, targetFile, preview)
+		return
+	}
 
-		snapshots[originalFile] = append(snapshots[originalFile], SnapshotInfo{
-			OriginalFile: originalFile,
-			Timestamp:    timestamp,
-			SnapshotFile: file.Name(),
-			Path:         filepath.Join(snapDir, file.Name()),
-		})
+	if before != "" {
+		if targetFile != "" {
+			snapshots = map[string][]SnapshotInfo{snapshotKey(targetFile): snapshotsOf(snapshots, targetFile)}
+		}
+		rollbackBatch(snapshots, before, preview)
+		return
 	}
 
 	// If specific file given, filter to that
-	if len(args) > 0 {
-		targetFile := args[0]
-		if snaps, ok := snapshots[targetFile]; ok {
-			snapshots = map[string][]SnapshotInfo{targetFile: snaps}
+	if targetFile != "" {
+		if snaps := snapshotsOf(snapshots, targetFile); len(snaps) > 0 {
+			snapshots = map[string][]SnapshotInfo{snapshotKey(targetFile): snaps}
 		} else {
 			logError(fmt.Sprintf("No snapshots found for: %s", targetFile))
 			return
@@ -72,17 +145,9 @@ func handleRollback(args []string) {
 	logInfo("Available snapshots:")
 	fmt.Println()
 
-	var allSnapshots []SnapshotInfo
-	for _, snaps := range snapshots {
-		// Sort by timestamp (newest first)
-		sort.Slice(snaps, func(i, j int) bool {
-			return snaps[i].Timestamp > snaps[j].Timestamp
-		})
-		allSnapshots = append(allSnapshots, snaps...)
-	}
-
+	allSnapshots := sortedSnapshots(snapshots)
 	for i, snap := range allSnapshots {
-		fmt.Printf("  %d. %s (from %s)\n", i+1, snap.OriginalFile, snap.Timestamp)
+		printSnapshotLine(i+1, snap)
 	}
 
 	printDivider()
@@ -103,35 +168,415 @@ func handleRollback(args []string) {
 
 	snapshot := allSnapshots[index-1]
 
+	if preview {
+		previewRestore(snapshot)
+	}
+
 	// Confirm
-	confirm := prompt(fmt.Sprintf("Restore %s? This will OVERWRITE current version. (y/n)", snapshot.OriginalFile))
-	if strings.ToLower(confirm) != "y" && strings.ToLower(confirm) != "yes" {
+	if !promptYesNo(fmt.Sprintf("Restore %s? This will OVERWRITE current version. (y/n)", snapshot.OriginalFile)) {
+		logInfo("Cancelled")
+		return
+	}
+
+	if err := restoreSnapshot(snapshot); err != nil {
+		logError(err.Error())
+		return
+	}
+
+	logSuccess(fmt.Sprintf("Restored: %s", snapshot.OriginalFile))
+	logInfo(fmt.Sprintf("From snapshot: %s", snapshot.Timestamp))
+}
+
+// listSnapshots prints every snapshot, or those of file, without prompting.
+// With --json it emits them as a list of SnapshotInfo
+func listSnapshots(snapshots map[string][]SnapshotInfo, file string) {
+	if file != "" {
+		snapshots = map[string][]SnapshotInfo{snapshotKey(file): snapshotsOf(snapshots, file)}
+	}
+	all := sortedSnapshots(snapshots)
+
+	if jsonMode {
+		if all == nil {
+			all = []SnapshotInfo{}
+		}
+		emitJSON(all)
+		return
+	}
+
+	if len(all) == 0 {
+		logInfo("No snapshots available")
+		return
+	}
+	printDivider()
+	for i, snap := range all {
+		printSnapshotLine(i+1, snap)
+	}
+	printDivider()
+	logInfo(fmt.Sprintf("%d snapshots. Restore one with: keke rollback <file> --at <timestamp>", len(all)))
+}
+
+// filterSnapshots keeps the files whose path or base name matches pattern,
+// e.g. '*.go', 'src/*' or 'main.go'
+func filterSnapshots(snapshots map[string][]SnapshotInfo, pattern string) map[string][]SnapshotInfo {
+	filtered := map[string][]SnapshotInfo{}
+	for key, snaps := range snapshots {
+		matched, _ := path.Match(pattern, key)
+		if !matched {
+			matched, _ = path.Match(pattern, path.Base(key))
+		}
+		if matched || key == snapshotKey(pattern) {
+			filtered[key] = snaps
+		}
+	}
+	return filtered
+}
+
+// snapshotsOf returns the snapshots of file, newest first, set to restore to
+// file as the user gave it
+func snapshotsOf(snapshots map[string][]SnapshotInfo, file string) []SnapshotInfo {
+	var snaps []SnapshotInfo
+	for _, snap := range snapshots[snapshotKey(file)] {
+		snap.OriginalFile = filepath.ToSlash(file)
+		snaps = append(snaps, snap)
+	}
+	return snaps
+}
+
+// sortedSnapshots flattens snapshots by file name, newest first per file
+func sortedSnapshots(snapshots map[string][]SnapshotInfo) []SnapshotInfo {
+	var names []string
+	for name := range snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var all []SnapshotInfo
+	for _, name := range names {
+		all = append(all, snapshots[name]...)
+	}
+	return all
+}
+
+func printSnapshotLine(n int, snap SnapshotInfo) {
+	label := ""
+	if snap.Name != "" {
+		label = fmt.Sprintf(" [%s]", snap.Name)
+	}
+	fmt.Printf("  %d. %s%s (from %s) %s%s%s\n", n, snap.OriginalFile, label, snap.Timestamp, dim, formatBytes(snap.Size), reset)
+}
+
+// rollbackAt restores the snapshot of file taken at timestamp
+func rollbackAt(snapshots map[string][]SnapshotInfo, file, timestamp string, preview bool) {
+	for _, snap := range snapshotsOf(snapshots, file) {
+		if snap.Name == "" && snap.Timestamp == timestamp {
+			confirmAndRestore([]SnapshotInfo{snap}, preview)
+			return
+		}
+	}
+	logError(fmt.Sprintf("No snapshot of %s at %s (see 'keke snapshots')", file, timestamp))
+}
+
+// rollbackSession undoes the most recent session of this project: every file
+// it wrote goes back to the snapshot taken before its first write. only
+// limits it to one of those files
+func rollbackSession(snapshots map[string][]SnapshotInfo, only string, preview bool) {
+	session, err := latestProjectSession()
+	if err != nil {
+		logError("No session found for this project")
+		return
+	}
+	if len(session.FilesWritten) == 0 {
+		logInfo(fmt.Sprintf("Session %s did not write any files", session.ID))
+		return
+	}
+
+	var selected []SnapshotInfo
+	for _, path := range session.FilesWritten {
+		if only != "" && snapshotKey(only) != snapshotKey(path) {
+			continue
+		}
+
+		pick := sessionStartSnapshot(snapshots, session, path)
+		if pick == nil {
+			logWarning(fmt.Sprintf("%s: no snapshot (created in this session?), skipped", path))
+			continue
+		}
+		selected = append(selected, *pick)
+	}
+
+	if len(selected) == 0 {
+		logInfo("Nothing to roll back")
+		return
+	}
+
+	logInfo(fmt.Sprintf("Undoing session %s (%s)", session.ID, truncateText(session.LastPrompt, 50)))
+	confirmAndRestore(selected, preview)
+}
+
+// sessionStartSnapshot returns the snapshot taken before session first wrote
+// path, or nil when there is none (the session created the file)
+func sessionStartSnapshot(snapshots map[string][]SnapshotInfo, session *SessionData, path string) *SnapshotInfo {
+	since := session.CreatedAt.Format(snapshotTimeFormat)
+	var pick *SnapshotInfo
+	snaps := snapshotsOf(snapshots, path)
+	for i := range snaps { // newest first
+		snap := snaps[i]
+		if snap.Name == "" && snap.Timestamp >= since {
+			pick = &snap // keep going to reach the oldest
+		}
+	}
+	return pick
+}
+
+// rollbackBatch returns every file to how it was before that time, at once:
+// snapshots are taken just ahead of a write, so the oldest snapshot at or
+// after before holds that content. Named snapshots are skipped
+func rollbackBatch(snapshots map[string][]SnapshotInfo, before string, preview bool) {
+	var selected []SnapshotInfo
+	for _, snaps := range snapshots {
+		var pick *SnapshotInfo
+		for i := range snaps { // newest first
+			snap := snaps[i]
+			if snap.Name != "" {
+				continue
+			}
+			if snap.Timestamp >= before {
+				pick = &snap // keep going to reach the oldest
+			}
+		}
+		if pick != nil {
+			selected = append(selected, *pick)
+		}
+	}
+
+	if len(selected) == 0 {
+		logInfo("Nothing to roll back")
+		return
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].OriginalFile < selected[j].OriginalFile
+	})
+	confirmAndRestore(selected, preview)
+}
+
+// confirmAndRestore lists what will be overwritten and restores it after a
+// single confirmation. With preview the diff of each file is shown first
+func confirmAndRestore(snaps []SnapshotInfo, preview bool) {
+	if preview {
+		for _, snap := range snaps {
+			previewRestore(snap)
+		}
+	}
+
+	printDivider()
+	logWarning("These files will be OVERWRITTEN:")
+	for _, snap := range snaps {
+		fmt.Printf("  %s•%s %s %s(from %s)%s\n", cyan, reset, snap.OriginalFile, dim, formatSnapshotTime(snap.Timestamp), reset)
+	}
+	printDivider()
+
+	noun := "files"
+	if len(snaps) == 1 {
+		noun = "file"
+	}
+	if !promptYesNo(fmt.Sprintf("Restore %d %s? (y/n)", len(snaps), noun)) {
 		logInfo("Cancelled")
 		return
 	}
 
-	// Read snapshot
-	content, err := ioutil.ReadFile(snapshot.Path)
+	restored := 0
+	for _, snap := range snaps {
+		if err := restoreSnapshot(snap); err != nil {
+			logError(err.Error())
+			continue
+		}
+		logSuccess(fmt.Sprintf("Restored: %s", snap.OriginalFile))
+		restored++
+	}
+	logInfo(fmt.Sprintf("Restored %d of %d files", restored, len(snaps)))
+}
+
+// restoreSnapshot writes a snapshot back over its original file. The current
+// version is snapshotted first so the restore can be undone too
+func restoreSnapshot(snap SnapshotInfo) error {
+	content, err := readSnapshot(snap)
+	if err != nil {
+		return fmt.Errorf("Failed to read snapshot: %v", err)
+	}
+	path := filepath.FromSlash(snap.OriginalFile)
+	if err := createSnapshot(path); err != nil && !os.IsNotExist(err) {
+		logWarning(fmt.Sprintf("Failed to snapshot %s before restoring: %v", snap.OriginalFile, err))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Failed to restore %s: %v", snap.OriginalFile, err)
+	}
+	// Keep the mode of the file being replaced, such as an executable script's
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, content, mode); err != nil {
+		return fmt.Errorf("Failed to restore %s: %v", snap.OriginalFile, err)
+	}
+	return nil
+}
+
+// previewRestore shows what restoring snap would change in its original file
+func previewRestore(snap SnapshotInfo) {
+	content, err := readSnapshot(snap)
 	if err != nil {
 		logError(fmt.Sprintf("Failed to read snapshot: %v", err))
 		return
 	}
+	current, _ := os.ReadFile(filepath.FromSlash(snap.OriginalFile)) // missing file diffs as empty
 
-	// Write to original location
-	if err := ioutil.WriteFile(snapshot.OriginalFile, content, 0644); err != nil {
-		logError(fmt.Sprintf("Failed to restore: %v", err))
+	printDivider()
+	logInfo(fmt.Sprintf("%s (from %s)", snap.OriginalFile, formatSnapshotTime(snap.Timestamp)))
+	if isBinary(content) || isBinary(current) {
+		logWarning(fmt.Sprintf("%s is a binary file, cannot show diff", snap.OriginalFile))
 		return
 	}
+	showPaged(diffFiles(current, content))
+}
 
-	logSuccess(fmt.Sprintf("Restored: %s", snapshot.OriginalFile))
-	logInfo(fmt.Sprintf("From snapshot: %s", snapshot.Timestamp))
+// ─── SNAPSHOT LISTING ────────────────────────────────────────────────────────
+
+// snapshotKey names the snapshots of path: its path from the project root
+// with forward slashes, so src/util.go and lib/util.go never share them.
+// Snapshots taken before they were keyed this way are listed by base name
+func snapshotKey(path string) string {
+	if rel, ok := projectRelativePath(path); ok {
+		path = rel
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// newSnapshotPath picks the file for a snapshot of key taken at t. A second
+// snapshot within the same second gets a -2, -3... suffix instead of
+// replacing the first
+func newSnapshotPath(key string, t time.Time) (string, error) {
+	base := filepath.Join(projectSnapshotsDir(), filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return "", err
+	}
+	stamp := t.Format(snapshotTimeFormat)
+	for seq := 1; ; seq++ {
+		ts := stamp
+		if seq > 1 {
+			ts = fmt.Sprintf("%s-%d", stamp, seq)
+		}
+		path := fmt.Sprintf("%s.%s.snap.gz", base, ts)
+		_, errGz := os.Stat(path)
+		_, errPlain := os.Stat(strings.TrimSuffix(path, ".gz"))
+		if os.IsNotExist(errGz) && os.IsNotExist(errPlain) {
+			return path, nil
+		}
+	}
+}
+
+// parseSnapshotTime reads a snapshot timestamp, ignoring any -N suffix
+func parseSnapshotTime(ts string) (time.Time, error) {
+	stamp, seq, found := strings.Cut(ts, "-")
+	if found {
+		if _, err := strconv.Atoi(seq); err != nil {
+			return time.Time{}, fmt.Errorf("invalid snapshot timestamp %s", ts)
+		}
+	}
+	return time.ParseInLocation(snapshotTimeFormat, stamp, time.Local)
+}
+
+// snapshotNewer orders timestamps newest first, -10 after -9
+func snapshotNewer(a, b string) bool {
+	stampA, seqA, _ := strings.Cut(a, "-")
+	stampB, seqB, _ := strings.Cut(b, "-")
+	if stampA != stampB {
+		return stampA > stampB
+	}
+	na, _ := strconv.Atoi(seqA)
+	nb, _ := strconv.Atoi(seqB)
+	return na > nb
+}
+
+// loadSnapshots reads .keke/snapshots/ and groups snapshots by snapshotKey
+func loadSnapshots() (map[string][]SnapshotInfo, error) {
+	snapDir := projectSnapshotsDir()
+	if _, err := os.Stat(snapDir); err != nil {
+		return nil, err
+	}
+
+	snapshots := make(map[string][]SnapshotInfo)
+	err := filepath.WalkDir(snapDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(snapDir, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		// Snapshots are gzipped; plain .snap files predate compression
+		compressed := strings.HasSuffix(rel, ".snap.gz")
+		base := strings.TrimSuffix(rel, ".gz")
+		if !strings.HasSuffix(base, ".snap") {
+			return nil
+		}
+
+		// Parse: dir/filename.timestamp.snap
+		parts := strings.Split(base, ".")
+		if len(parts) < 3 {
+			return nil
+		}
+
+		originalFile := strings.Join(parts[:len(parts)-2], ".")
+		timestamp := parts[len(parts)-2]
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		// Named snapshots (filename.name.snap) are dated by modification time
+		name := ""
+		if _, err := parseSnapshotTime(timestamp); err != nil {
+			name = timestamp
+			timestamp = info.ModTime().Format(snapshotTimeFormat)
+		}
+
+		snapshots[originalFile] = append(snapshots[originalFile], SnapshotInfo{
+			OriginalFile: originalFile,
+			Timestamp:    timestamp,
+			Name:         name,
+			SnapshotFile: rel,
+			Path:         path,
+			Size:         info.Size(),
+			Compressed:   compressed,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort by timestamp (newest first)
+	for _, snaps := range snapshots {
+		sort.Slice(snaps, func(i, j int) bool {
+			return snapshotNewer(snaps[i].Timestamp, snaps[j].Timestamp)
+		})
+	}
+
+	return snapshots, nil
 }
 
 // ─── TYPES ───────────────────────────────────────────────────────────────────
 
 type SnapshotInfo struct {
-	OriginalFile string
-	Timestamp    string
-	SnapshotFile string
-	Path         string
+	OriginalFile string `json:"original_file"` // snapshotKey of the file
+	Timestamp    string `json:"timestamp"`
+	Name         string `json:"name,omitempty"` // set for named snapshots (keke snapshot save)
+	SnapshotFile string `json:"snapshot_file"`  // relative to .keke/snapshots/
+	Path         string `json:"path"`
+	Size         int64  `json:"size"` // on disk, compressed or not
+	Compressed   bool   `json:"compressed"`
 }
\ No newline at end of file
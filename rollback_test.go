@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestRollbackLatestRestoresGivenPath(t *testing.T) {
+	newTestProject(t)
+	writeTestFile(t, "src/auth.go", "v1")
+	if err := createSnapshot("src/auth.go"); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, "src/auth.go", "v2")
+
+	handleRollback([]string{"src/auth.go", "--latest"})
+
+	if got := readTestFile(t, "src/auth.go"); got != "v1" {
+		t.Errorf("src/auth.go = %q after --latest, want %q", got, "v1")
+	}
+}
+
+func TestRollbackKeepsFileMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no executable bit on windows")
+	}
+	newTestProject(t)
+	writeTestFile(t, "run.sh", "echo v1\n")
+	if err := os.Chmod("run.sh", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := createSnapshot("run.sh"); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, "run.sh", "echo v2\n")
+
+	handleRollback([]string{"run.sh", "--latest"})
+
+	info, err := os.Stat("run.sh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("run.sh mode = %v after rollback, want 0755", info.Mode().Perm())
+	}
+}
+
+func TestFilterSnapshots(t *testing.T) {
+	newTestProject(t)
+	snapshots := map[string][]SnapshotInfo{
+		"main.go":     {{OriginalFile: "main.go"}},
+		"src/auth.go": {{OriginalFile: "src/auth.go"}},
+		"README.md":   {{OriginalFile: "README.md"}},
+	}
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*.go", []string{"main.go", "src/auth.go"}},
+		{"src/*", []string{"src/auth.go"}},
+		{"auth.go", []string{"src/auth.go"}},
+		{"./README.md", []string{"README.md"}},
+		{"*.txt", nil},
+	}
+	for _, tt := range tests {
+		got := filterSnapshots(snapshots, tt.pattern)
+		if len(got) != len(tt.want) {
+			t.Errorf("filterSnapshots(%q) kept %d files, want %v", tt.pattern, len(got), tt.want)
+			continue
+		}
+		for _, key := range tt.want {
+			if _, ok := got[key]; !ok {
+				t.Errorf("filterSnapshots(%q) dropped %s", tt.pattern, key)
+			}
+		}
+	}
+}
+
+func TestRollbackAtAndBeforeUseProjectPath(t *testing.T) {
+	for _, flag := range []string{"--at", "--before"} {
+		t.Run(flag, func(t *testing.T) {
+			newTestProject(t)
+			writeTestFile(t, "src/auth.go", "v1")
+			if err := createSnapshot("src/auth.go"); err != nil {
+				t.Fatal(err)
+			}
+			snapshots, err := loadSnapshots()
+			if err != nil || len(snapshots["src/auth.go"]) != 1 {
+				t.Fatalf("snapshot of src/auth.go not found: %v", err)
+			}
+			stamp := snapshots["src/auth.go"][0].Timestamp
+			writeTestFile(t, "src/auth.go", "v2")
+
+			handleRollback([]string{"./src/auth.go", flag, stamp})
+
+			if got := readTestFile(t, "src/auth.go"); got != "v1" {
+				t.Errorf("src/auth.go = %q after %s, want %q", got, flag, "v1")
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ─── SANDBOX ─────────────────────────────────────────────────────────────────
+// With --sandbox (or 'keke config set sandbox true') AI-requested commands run
+// in a throwaway Docker container with only the project mounted at /workspace
+
+func sandboxEnabled() bool {
+	return sandboxFlag || sandboxRequired || getConfig().Sandbox
+}
+
+// checkDocker reports why Docker can't be used, or nil when it can
+func checkDocker() error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return fmt.Errorf("docker is not installed")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if out, err := exec.CommandContext(ctx, "docker", "info").CombinedOutput(); err != nil {
+		return fmt.Errorf("docker is not running: %s", firstLine(string(out)))
+	}
+	return nil
+}
+
+// Docker is checked once per run; the fallback warning is printed once too
+var dockerErr error
+var dockerChecked bool
+
+// shellCommand builds the command that runs command, inside the sandbox when
+// it is enabled. container is the name of the sandbox container, "" when the
+// command runs directly. Without Docker it falls back to running directly,
+// unless --sandbox-required was given
+func shellCommand(ctx context.Context, command string) (cmd *exec.Cmd, container string, err error) {
+	if !sandboxEnabled() {
+		return exec.CommandContext(ctx, "sh", "-c", command), "", nil
+	}
+
+	if !dockerChecked {
+		dockerErr = checkDocker()
+		dockerChecked = true
+		if dockerErr != nil && !sandboxRequired {
+			logWarning(fmt.Sprintf("Sandbox unavailable (%v), running commands directly", dockerErr))
+		}
+	}
+	if dockerErr != nil {
+		if sandboxRequired {
+			return nil, "", fmt.Errorf("sandbox required but %v", dockerErr)
+		}
+		return exec.CommandContext(ctx, "sh", "-c", command), "", nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, "", err
+	}
+	container = newContainerName()
+	cmd = exec.CommandContext(ctx, "docker", "run", "--rm",
+		"--name", container,
+		"-v", cwd+":/workspace",
+		"-w", "/workspace",
+		getConfig().SandboxImage,
+		"sh", "-c", command)
+	return cmd, container, nil
+}
+
+func newContainerName() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "keke-sandbox-" + hex.EncodeToString(b)
+}
+
+// stopContainer kills a sandbox container left running after a timeout;
+// killing the docker client alone does not stop it
+func stopContainer(container string) {
+	if container != "" {
+		exec.Command("docker", "kill", container).Run()
+	}
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// ─── SANDBOX COMMAND ─────────────────────────────────────────────────────────
+
+func handleSandbox(args []string) {
+	if len(args) == 0 || args[0] != "test" {
+		logError("Usage: keke sandbox test")
+		logInfo("Enable with --sandbox or: keke config set sandbox true")
+		return
+	}
+
+	image := getConfig().SandboxImage
+	logInfo(fmt.Sprintf("Checking Docker (image %s)...", image))
+
+	if err := checkDocker(); err != nil {
+		logError(err.Error())
+		exitCode = exitError
+		return
+	}
+	logSuccess("Docker is available")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		logError(err.Error())
+		exitCode = exitError
+		return
+	}
+
+	// Write a file from inside the container and look for it on the host
+	probe := fmt.Sprintf(".keke-sandbox-test-%s", newContainerName()[len("keke-sandbox-"):])
+	defer os.Remove(filepath.Join(cwd, probe))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "docker", "run", "--rm",
+		"-v", cwd+":/workspace", "-w", "/workspace", image,
+		"sh", "-c", "echo ok > "+probe+" && cat "+probe).CombinedOutput()
+	if err != nil {
+		logError(fmt.Sprintf("Sandbox run failed: %s", firstLine(string(out))))
+		exitCode = exitError
+		return
+	}
+	logSuccess(fmt.Sprintf("Container ran in %s", image))
+
+	content, err := os.ReadFile(filepath.Join(cwd, probe))
+	if err != nil || strings.TrimSpace(string(content)) != "ok" {
+		logError(fmt.Sprintf("%s is not writable from the container", cwd))
+		exitCode = exitError
+		return
+	}
+	logSuccess(fmt.Sprintf("%s is mounted read-write at /workspace", cwd))
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ─── SCAFFOLD ────────────────────────────────────────────────────────────────
+// 'keke scaffold' lists the project templates in the templates_repo GitHub
+// repository; 'keke scaffold <name>' copies one into the current directory,
+// initializes Keke and runs the template's post-install commands.
+// The repository has a templates.json index at its root and one directory
+// per template holding the files and a keke-template.json manifest
+
+const templateManifestFile = "keke-template.json"
+
+// TemplateManifest - keke-template.json, also the entries of templates.json
+type TemplateManifest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Requires    []string `json:"requires,omitempty"`     // tools that must be on PATH, e.g. go, node
+	PostInstall []string `json:"post_install,omitempty"` // commands run after the files are copied
+}
+
+func handleScaffold(args []string) {
+	name := ""
+	force := forceFlag // --force is a global flag
+	for _, arg := range args {
+		switch {
+		case name == "" && !strings.HasPrefix(arg, "-"):
+			name = arg
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", arg))
+			logInfo("Usage: keke scaffold [template] [--force]")
+			return
+		}
+	}
+
+	repo := getConfig().TemplatesRepo
+	if name == "" {
+		listTemplates(repo)
+		return
+	}
+
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		logError(fmt.Sprintf("Invalid template name: %s", name))
+		return
+	}
+
+	logInfo(fmt.Sprintf("Downloading template %s from %s...", name, repo))
+	archive, err := downloadFile(fmt.Sprintf("https://api.github.com/repos/%s/tarball", repo))
+	if err != nil {
+		logRequestError("Failed to download templates", err)
+		return
+	}
+
+	files, manifest, err := templateFiles(archive, name)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	if len(files) == 0 {
+		logError(fmt.Sprintf("Template '%s' not found in %s", name, repo))
+		logInfo("Run 'keke scaffold' to list templates")
+		return
+	}
+
+	// Never overwrite existing work unless asked to
+	if !force {
+		var existing []string
+		for _, f := range files {
+			if _, err := os.Stat(f.Path); err == nil {
+				existing = append(existing, f.Path)
+			}
+		}
+		if len(existing) > 0 {
+			logError(fmt.Sprintf("%d files already exist: %s", len(existing), strings.Join(existing, ", ")))
+			logInfo("Use --force to overwrite them")
+			return
+		}
+	}
+
+	for _, f := range files {
+		if dryRun {
+			logInfo(fmt.Sprintf("[DRY RUN] Would create %s (%d bytes)", f.Path, len(f.Content)))
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			logError(fmt.Sprintf("Failed to create %s: %v", filepath.Dir(f.Path), err))
+			return
+		}
+		if err := os.WriteFile(f.Path, f.Content, f.Mode); err != nil {
+			logError(fmt.Sprintf("Failed to write %s: %v", f.Path, err))
+			return
+		}
+	}
+	if !dryRun {
+		logSuccess(fmt.Sprintf("Created %d files from template %s", len(files), name))
+	}
+
+	missing := missingTools(manifest.Requires)
+	if len(missing) > 0 {
+		logWarning(fmt.Sprintf("Missing required tools: %s", strings.Join(missing, ", ")))
+	}
+
+	if !isProjectInitialized() && !dryRun {
+		handleInit()
+	}
+
+	if len(manifest.PostInstall) == 0 {
+		return
+	}
+	if len(missing) > 0 {
+		logWarning("Skipping post-install commands until the missing tools are installed:")
+		for _, command := range manifest.PostInstall {
+			logInfo(fmt.Sprintf("  %s", command))
+		}
+		return
+	}
+
+	for _, command := range manifest.PostInstall {
+		result := handleExecuteCommand(Action{Type: "execute_command", Command: command})
+		if actionFailed(result) {
+			logWarning(fmt.Sprintf("Post-install command did not complete: %s", command))
+			fmt.Println(strings.TrimSpace(result))
+			return
+		}
+	}
+	logSuccess(fmt.Sprintf("Template %s is ready", name))
+}
+
+// listTemplates prints the templates.json index of repo
+func listTemplates(repo string) {
+	data, err := downloadFile(fmt.Sprintf("https://raw.githubusercontent.com/%s/HEAD/templates.json", repo))
+	if err != nil {
+		logError(fmt.Sprintf("Failed to fetch template list from %s: %v", repo, err))
+		return
+	}
+
+	var templates []TemplateManifest
+	if err := json.Unmarshal(data, &templates); err != nil {
+		logError(fmt.Sprintf("Invalid templates.json in %s: %v", repo, err))
+		return
+	}
+
+	if jsonMode {
+		emitJSON(templates)
+		return
+	}
+
+	if len(templates) == 0 {
+		logInfo(fmt.Sprintf("No templates in %s", repo))
+		return
+	}
+
+	printHeader()
+	logInfo(fmt.Sprintf("Templates in %s", repo))
+	printDivider()
+	for _, t := range templates {
+		desc := t.Description
+		if len(t.Requires) > 0 {
+			desc += fmt.Sprintf(" (needs %s)", strings.Join(t.Requires, ", "))
+		}
+		printCmd(t.Name, desc)
+	}
+	printDivider()
+	logInfo("Create one with: keke scaffold <name>")
+}
+
+// templateFile - one file of a template, with its path relative to the project
+type templateFile struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+}
+
+// templateFiles picks the files of template name out of a GitHub tarball,
+// whose entries all sit under a single <owner>-<repo>-<sha>/ directory
+func templateFiles(archive []byte, name string) ([]templateFile, *TemplateManifest, error) {
+	var files []templateFile
+	manifest := &TemplateManifest{Name: name}
+
+	err := eachTarGzFile(archive, func(header *tar.Header, r io.Reader) (bool, error) {
+		parts := strings.SplitN(header.Name, "/", 3)
+		if len(parts) < 3 || parts[1] != name {
+			return false, nil
+		}
+		rel := filepath.Clean(filepath.FromSlash(parts[2]))
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return false, fmt.Errorf("template entry escapes the project: %s", header.Name)
+		}
+
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return false, err
+		}
+		if rel == templateManifestFile {
+			if err := json.Unmarshal(content, manifest); err != nil {
+				return false, fmt.Errorf("invalid %s: %v", templateManifestFile, err)
+			}
+			return false, nil
+		}
+		files = append(files, templateFile{Path: rel, Content: content, Mode: os.FileMode(header.Mode).Perm() | 0600})
+		return false, nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to read template archive: %v", err)
+	}
+	return files, manifest, nil
+}
+
+// missingTools returns the tools that are not on PATH
+func missingTools(tools []string) []string {
+	var missing []string
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ─── SEARCH ──────────────────────────────────────────────────────────────────
+// 'keke search <query>' sends the project's text files to the AI and prints
+// the places most relevant to the query
+
+// Files larger than this are not sent
+const maxSearchFileBytes = 500 * 1024
+
+// Stop adding files once this much content has been collected
+const maxSearchContextBytes = 2 * 1024 * 1024
+
+// SearchResult - one ranked match returned in search mode
+type SearchResult struct {
+	File       string  `json:"file"`
+	LineNumber int     `json:"line_number"`
+	Snippet    string  `json:"snippet"`
+	Relevance  float64 `json:"relevance"` // 0-1
+}
+
+// Sent with the files so the reply is a single JSON object of results
+const searchInstruction = `Find the code most relevant to the query below in the project files that follow. Reply with ONLY a JSON object, no prose and no code fences, in this shape:
+{"results": [{"file": "path", "line_number": 42, "snippet": "matching line(s)", "relevance": 0.9}]}`
+
+func handleSearch(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	model := getConfig().DefaultModel
+	var queryParts []string
+	for _, arg := range args {
+		switch arg {
+		case "--fast", "--smart", "--deep":
+			model = strings.TrimPrefix(arg, "--")
+		default:
+			queryParts = append(queryParts, arg)
+		}
+	}
+
+	query := strings.Join(queryParts, " ")
+	if query == "" {
+		logError("Usage: keke search \"where are sessions saved?\"")
+		return
+	}
+
+	context, count, err := collectSearchContext(".")
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read project files: %v", err))
+		return
+	}
+	if count == 0 {
+		logError("No text files to search")
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("AI searching %d files (%s)...", count, formatBytes(int64(len(context)))))
+
+	results, credits, err := callSearchAI(query, context, model, auth)
+	if err != nil {
+		logRequestError("AI error", err)
+		return
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Relevance > results[j].Relevance
+	})
+
+	if jsonMode {
+		emitJSON(results)
+		return
+	}
+
+	displaySearchResults(query, results)
+	printDivider()
+	logInfo(fmt.Sprintf("Credits used: %d", credits))
+}
+
+// collectSearchContext concatenates every searchable file under dir,
+// skipping ignored, binary and oversized files
+func collectSearchContext(dir string) (string, int, error) {
+	ignore := loadIgnorePatterns()
+
+	var b strings.Builder
+	count := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if isIgnored(path, info.IsDir(), ignore) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || info.Size() > maxSearchFileBytes {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil // unreadable files are skipped
+		}
+		head := content
+		if len(head) > 512 {
+			head = head[:512]
+		}
+		if isBinary(head) {
+			return nil
+		}
+
+		if b.Len()+len(content) > maxSearchContextBytes {
+			logWarning(fmt.Sprintf("Project too large, skipping %s and later files", path))
+			return filepath.SkipAll
+		}
+
+		fmt.Fprintf(&b, "=== %s ===\n", filepath.ToSlash(path))
+		for i, line := range splitLines(string(content)) {
+			fmt.Fprintf(&b, "%d| %s\n", i+1, line)
+		}
+		count++
+		return nil
+	})
+	return b.String(), count, err
+}
+
+func callSearchAI(query, context, model string, auth *AuthData) ([]SearchResult, int, error) {
+	payload := map[string]interface{}{
+		"conversation": []map[string]string{
+			{"role": "user", "content": fmt.Sprintf("%s\n\nQuery: %s\n\n%s", searchInstruction, query, context)},
+		},
+		"model": model,
+		"mode":  "search", // Search only, no actions
+	}
+
+	response, err := postAI(payload, auth, false)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var reply struct {
+		Results []SearchResult `json:"results"`
+	}
+	if err := decodeJSONReply(response.Message, &reply); err != nil {
+		return nil, response.CreditsUsed, fmt.Errorf("invalid search results: %v", err)
+	}
+	return reply.Results, response.CreditsUsed, nil
+}
+
+// displaySearchResults prints file:line headings with the snippet below,
+// query words highlighted
+func displaySearchResults(query string, results []SearchResult) {
+	printDivider()
+	if len(results) == 0 {
+		logInfo("No matches found")
+		return
+	}
+
+	var words []string
+	for _, word := range strings.Fields(query) {
+		if len(word) > 2 {
+			words = append(words, regexp.QuoteMeta(word))
+		}
+	}
+	var highlight *regexp.Regexp
+	if len(words) > 0 {
+		highlight = regexp.MustCompile(`(?i)` + strings.Join(words, "|"))
+	}
+
+	for i, result := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s%s:%d%s %s(%.0f%%)%s\n", cyan, result.File, result.LineNumber, reset,
+			dim, result.Relevance*100, reset)
+		for _, line := range splitLines(result.Snippet) {
+			if highlight != nil {
+				line = highlight.ReplaceAllStringFunc(line, func(match string) string {
+					return bold + yellow + match + reset
+				})
+			}
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
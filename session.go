@@ -0,0 +1,325 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ─── SESSION ─────────────────────────────────────────────────────────────────
+// Conversations are saved to ~/.keke/sessions/<id>.json after every AI turn so
+// they can be resumed with --session <id> from any directory. The newest
+// session of a project stops being active after sessionTTL.
+
+const sessionTTL = time.Hour
+
+// SessionData - conversation state persisted between runs
+type SessionData struct {
+	ID           string              `json:"id"`
+	Project      string              `json:"project"` // directory it was started in
+	Mode         string              `json:"mode"`    // ask, research, docs
+	Model        string              `json:"model"`
+	Provider     string              `json:"provider,omitempty"` // provider when the session started
+	LastPrompt   string              `json:"last_prompt"`
+	History      []map[string]string `json:"history"`
+	FilesWritten []string            `json:"files_written,omitempty"` // paths the AI wrote, for 'keke export' and 'keke rollback --all'
+	CreditsUsed  int                 `json:"credits_used"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// newSession starts a session for a fresh conversation
+func newSession(mode, model string) *SessionData {
+	now := time.Now()
+	cwd, _ := os.Getwd()
+	return &SessionData{
+		ID:        newSessionID(),
+		Project:   cwd,
+		Mode:      mode,
+		Model:     model,
+		Provider:  aiProvider(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// openSession resumes the session given by --session, or this project's
+// active session with --continue, or starts a new one
+func openSession(mode string, opts *askOptions) (*SessionData, error) {
+	if opts.Continue && opts.SessionID == "" {
+		session, err := latestProjectSession()
+		if err != nil {
+			return nil, fmt.Errorf("no session to continue in this project")
+		}
+		if age := time.Since(session.UpdatedAt); age >= sessionTTL {
+			return nil, fmt.Errorf("session %s expired %s ago (sessions stay active for %s after their last use). Resume it anyway with --session %s",
+				session.ID, formatAge(age-sessionTTL), formatAge(sessionTTL), session.ID)
+		}
+		logInfo(fmt.Sprintf("Continuing session %s (%d messages)", session.ID, len(session.History)))
+		return session, nil
+	}
+	if opts.SessionID == "" {
+		return newSession(mode, opts.Model), nil
+	}
+
+	session, err := loadSession(opts.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resume session %s: %v", opts.SessionID, err)
+	}
+	logInfo(fmt.Sprintf("Resuming session %s (%d messages)", session.ID, len(session.History)))
+	return session, nil
+}
+
+// recordAction remembers files written by a successful write_file action
+func (s *SessionData) recordAction(action Action, result string) {
+	if action.Type != "write_file" || dryRun || !strings.HasPrefix(result, "Successfully wrote") {
+		return
+	}
+	for _, path := range s.FilesWritten {
+		if path == action.Path {
+			return
+		}
+	}
+	s.FilesWritten = append(s.FilesWritten, action.Path)
+}
+
+func newSessionID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().Format("20060102") + "-" + hex.EncodeToString(b)
+}
+
+func sessionFile(id string) string {
+	return filepath.Join(globalSessionsDir(), id+".json")
+}
+
+// loadSession reads session id, or the newest session of this project when
+// id is empty
+func loadSession(id string) (*SessionData, error) {
+	if id == "" {
+		return latestProjectSession()
+	}
+
+	if strings.ContainsAny(id, `/\.`) {
+		return nil, fmt.Errorf("invalid session id %s", id)
+	}
+
+	data, err := os.ReadFile(sessionFile(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no session with id %s", id)
+		}
+		return nil, err
+	}
+	var session SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("corrupt session file: %v", err)
+	}
+	return &session, nil
+}
+
+func saveSession(session *SessionData) error {
+	if err := os.MkdirAll(globalSessionsDir(), 0700); err != nil {
+		return err
+	}
+	session.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sessionFile(session.ID), data, 0600)
+}
+
+// clearSession deletes session id, or this project's newest session when id
+// is empty
+func clearSession(id string) error {
+	if id == "" {
+		session, err := latestProjectSession()
+		if err != nil {
+			return nil // nothing to clear
+		}
+		id = session.ID
+	}
+
+	err := os.Remove(sessionFile(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// hasActiveSession reports whether this project's newest session was used
+// within sessionTTL
+func hasActiveSession() bool {
+	session, err := latestProjectSession()
+	if err != nil {
+		return false
+	}
+	return time.Since(session.UpdatedAt) < sessionTTL
+}
+
+// listSessions returns all saved sessions, most recently used first
+func listSessions() ([]*SessionData, error) {
+	entries, err := os.ReadDir(globalSessionsDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var sessions []*SessionData
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		session, err := loadSession(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue // skip unreadable files
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+	return sessions, nil
+}
+
+func latestProjectSession() (*SessionData, error) {
+	sessions, err := listSessions()
+	if err != nil {
+		return nil, err
+	}
+	cwd, _ := os.Getwd()
+	for _, session := range sessions {
+		if session.Project == cwd {
+			return session, nil
+		}
+	}
+	return nil, fmt.Errorf("no session for this project")
+}
+
+// ─── SESSION COMMAND ─────────────────────────────────────────────────────────
+// 'keke session' shows this project's newest session and whether it is still
+// active; 'keke session clear' deletes it
+
+func handleSession(args []string) {
+	if len(args) > 0 {
+		if args[0] != "clear" || len(args) > 1 {
+			logError(fmt.Sprintf("Unknown subcommand: %s", strings.Join(args, " ")))
+			logInfo("Usage: keke session [clear]")
+			return
+		}
+		session, err := latestProjectSession()
+		if err != nil {
+			logInfo("No session to clear")
+			return
+		}
+		if err := clearSession(session.ID); err != nil {
+			logError(fmt.Sprintf("Failed to clear session: %v", err))
+			return
+		}
+		logSuccess(fmt.Sprintf("Cleared session %s", session.ID))
+		return
+	}
+
+	session, err := latestProjectSession()
+	if err != nil {
+		logInfo("No session in this project yet. Start one with 'keke ask'")
+		return
+	}
+
+	provider := session.Provider
+	if provider == "" {
+		provider = "default"
+	}
+	idle := time.Since(session.UpdatedAt)
+
+	if jsonMode {
+		emitJSON(map[string]interface{}{
+			"id":         session.ID,
+			"mode":       session.Mode,
+			"model":      session.Model,
+			"provider":   provider,
+			"last":       session.LastPrompt,
+			"messages":   len(session.History),
+			"created_at": session.CreatedAt,
+			"updated_at": session.UpdatedAt,
+			"active":     idle < sessionTTL,
+		})
+		return
+	}
+
+	printDivider()
+	logInfo(fmt.Sprintf("Session:      %s", session.ID))
+	logInfo(fmt.Sprintf("Model:        %s (%s mode, provider %s)", session.Model, session.Mode, provider))
+	logInfo(fmt.Sprintf("Last command: keke %s \"%s\"", session.Mode, truncateText(session.LastPrompt, 50)))
+	logInfo(fmt.Sprintf("Messages:     %d (%d credits)", len(session.History), session.CreditsUsed))
+	logInfo(fmt.Sprintf("Age:          started %s ago, last used %s ago", formatAge(time.Since(session.CreatedAt)), formatAge(idle)))
+	if idle < sessionTTL {
+		logSuccess(fmt.Sprintf("Active:       expires in %s unless used again", formatAge(sessionTTL-idle)))
+		logInfo(fmt.Sprintf("Continue with: keke %s --continue \"follow-up prompt\"", session.Mode))
+	} else {
+		logWarning(fmt.Sprintf("Expired:      %s ago, sessions stay active for %s after their last use", formatAge(idle-sessionTTL), formatAge(sessionTTL)))
+		logInfo(fmt.Sprintf("Still saved, resume with: keke %s --session %s \"follow-up prompt\"", session.Mode, session.ID))
+	}
+	printDivider()
+}
+
+// ─── SESSIONS COMMAND ────────────────────────────────────────────────────────
+
+func handleSessions(args []string) {
+	if len(args) > 0 && args[0] != "list" {
+		logError(fmt.Sprintf("Unknown subcommand: %s", args[0]))
+		logInfo("Usage: keke sessions list")
+		return
+	}
+
+	sessions, err := listSessions()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read sessions: %v", err))
+		return
+	}
+
+	if len(sessions) > 10 {
+		sessions = sessions[:10]
+	}
+
+	if jsonMode {
+		list := []map[string]interface{}{}
+		for _, session := range sessions {
+			list = append(list, map[string]interface{}{
+				"id":           session.ID,
+				"mode":         session.Mode,
+				"project":      session.Project,
+				"last":         session.LastPrompt,
+				"credits_used": session.CreditsUsed,
+				"updated_at":   session.UpdatedAt,
+			})
+		}
+		emitJSON(list)
+		return
+	}
+
+	if len(sessions) == 0 {
+		logInfo("No saved sessions")
+		return
+	}
+
+	printDivider()
+	for _, session := range sessions {
+		fmt.Printf("%s%s%s  %s%s, %s ago, %d credits%s\n",
+			bold, session.ID, reset, dim, session.Mode, formatAge(time.Since(session.UpdatedAt)), session.CreditsUsed, reset)
+		fmt.Printf("  %s\n", truncateText(session.LastPrompt, 70))
+		fmt.Printf("  %s%s%s\n", dim, session.Project, reset)
+	}
+	printDivider()
+	logInfo("Resume with: keke ask --session <id> \"follow-up prompt\"")
+}
@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // ═══════════════════════════════════════════════════════════════════════════
-// SIGNAL - Forex Market Analysis & Trading Predictions
+// SIGNAL - Market Analysis & Trading Predictions
 // ═══════════════════════════════════════════════════════════════════════════
-// AI analyzes forex pairs and predicts:
+// AI analyzes forex pairs, stocks and crypto and predicts:
 // - Trade direction (BUY/SELL/HOLD)
 // - Entry price
 // - Take Profit (TP)
@@ -24,35 +28,81 @@ import (
 // Does NOT execute trades - only predicts and advises
 
 func handleSignal(args []string) {
+	if len(args) > 0 && args[0] == "watch" {
+		handleSignalWatch(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == "history" {
+		handleSignalHistory(args[1:])
+		return
+	}
+
+	if len(args) > 0 && args[0] == "backtest" {
+		handleSignalBacktest(args[1:])
+		return
+	}
+
 	if !isLoggedIn() {
 		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
 		return
 	}
 
 	if len(args) == 0 {
-		logError("Usage: keke signal <PAIR> [--timeframe 1H|4H|1D]")
+		logError("Usage: keke signal <SYMBOL>... [--timeframe 1H|4H|1D] [--provider P] [--full] [--multi] [--filter BUY|SELL|HOLD] [--alert EMAIL] [--alert-threshold 75]")
 		logInfo("Examples:")
 		logInfo("  keke signal EURUSD")
 		logInfo("  keke signal GBPUSD --timeframe 4H")
 		logInfo("  keke signal XAUUSD --timeframe 1D")
 		logInfo("  keke signal BTCUSD --timeframe 1H")
+		logInfo("  keke signal EURUSD GBPUSD USDJPY --timeframe 1D")
+		logInfo("  keke signal --multi EURUSD GBPUSD XAUUSD --filter BUY")
+		logInfo("  keke signal XAUUSD --alert me@example.com --alert-threshold 80")
+		logInfo("  keke signal EURUSD --json")
+		logInfo("  keke signal watch add EURUSD --timeframe 4H")
+		logInfo("  keke signal history EURUSD --limit 10")
+		logInfo("  keke signal backtest EURUSD 2025-01-01 2025-06-30")
 		return
 	}
 
 	// Parse arguments
-	pair := strings.ToUpper(args[0])
-	timeframe := "4H" // default
+	var pairs []string
+	timeframe := getConfig().DefaultTimeframe
+	provider := aiProvider()
+	full, multi := false, false
+	filter := ""
+	alert := &alertOptions{Threshold: defaultAlertThreshold}
 
-	for i := 1; i < len(args); i++ {
-		if args[i] == "--timeframe" && i+1 < len(args) {
-			timeframe = strings.ToUpper(args[i+1])
+	for i := 0; i < len(args); i++ {
+		if n, err := parseAlertFlag(args, i, alert); err != nil {
+			logError(err.Error())
+			return
+		} else if n > 0 {
+			i += n - 1
+		} else if args[i] == "--timeframe" && i+1 < len(args) {
+			timeframe = args[i+1]
+			i++
+		} else if args[i] == "--full" {
+			full = true
+		} else if args[i] == "--multi" {
+			multi = true
+		} else if args[i] == "--filter" && i+1 < len(args) {
+			filter = strings.ToUpper(args[i+1])
 			i++
+		} else {
+			pairs = append(pairs, strings.ToUpper(args[i]))
 		}
 	}
 
-	// Validate pair format
-	if len(pair) < 6 {
-		logError("Invalid pair format. Examples: EURUSD, GBPUSD, XAUUSD, BTCUSD")
+	timeframe, err := normalizeTimeframe(timeframe)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+
+	if len(pairs) == 0 {
+		logError("No pair given. Example: keke signal EURUSD")
 		return
 	}
 
@@ -62,14 +112,47 @@ func handleSignal(args []string) {
 		return
 	}
 
-	logInfo(fmt.Sprintf("🔍 Analyzing %s on %s timeframe...", pair, timeframe))
-	logInfo("AI is thinking deeply about market conditions...")
-	printDivider()
+	if filter != "" && filter != "BUY" && filter != "SELL" && filter != "HOLD" {
+		logError(fmt.Sprintf("Invalid --filter %s (use BUY, SELL or HOLD)", filter))
+		return
+	}
+
+	retryPendingAlerts(auth)
+
+	if len(pairs) > 1 || multi || filter != "" {
+		items := make([]WatchItem, len(pairs))
+		for i, pair := range pairs {
+			items[i] = WatchItem{Symbol: pair, Timeframe: timeframe, Provider: provider}
+		}
+		runSignalBatch(items, full, filter, alert, auth)
+		return
+	}
+
+	pair := pairs[0]
+
+	if err := validateSymbol(pair); err != nil {
+		logError(err.Error())
+		return
+	}
+
+	// In JSON mode only the signal itself is reported
+	if !jsonMode {
+		logInfo(fmt.Sprintf("🔍 Analyzing %s on %s timeframe...", pair, timeframe))
+		logInfo("AI is thinking deeply about market conditions...")
+		printDivider()
+	}
 
 	// Call AI for market analysis
-	signal, err := getForexSignal(pair, timeframe, auth)
+	signal, err := getTradeSignal(pair, timeframe, provider, auth)
 	if err != nil {
-		logError(fmt.Sprintf("Signal error: %v", err))
+		logRequestError("Signal error", err)
+		return
+	}
+
+	sendSignalAlerts([]*TradeSignal{signal}, alert, auth)
+
+	if jsonMode {
+		emitJSON(signal)
 		return
 	}
 
@@ -82,19 +165,190 @@ func handleSignal(args []string) {
 }
 
 // ═══════════════════════════════════════════════════════════════════════════
-// GET FOREX SIGNAL (calls edge function)
+// BATCH (several pairs, or the watchlist)
+// ═══════════════════════════════════════════════════════════════════════════
+
+// At most this many signal requests run at once
+const signalConcurrency = 3
+
+// runSignalBatch fetches a signal per item concurrently and shows them as one
+// table (or in full with --full), keeping the order of items. Each request is
+// bounded by the HTTP client timeout; pairs that fail are reported without
+// stopping the rest. filter keeps only one direction; the signals shown are
+// also the ones alerted on
+func runSignalBatch(items []WatchItem, full bool, filter string, alert *alertOptions, auth *AuthData) {
+	// Refresh once up front; every request then works on its own copy, and
+	// one rejected mid-batch picks up whichever renewal ran first (renewAuth)
+	if tokenExpiring(auth) {
+		if err := renewAuth(auth, auth.AccessToken); err != nil {
+			logError(err.Error())
+			return
+		}
+	}
+
+	results := make([]*TradeSignal, len(items))
+	errs := make([]error, len(items))
+	sem := make(chan struct{}, signalConcurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		if err := validateSymbol(item.Symbol); err != nil {
+			errs[i] = err
+			continue
+		}
+
+		if !jsonMode {
+			logInfo(fmt.Sprintf("🔍 Analyzing %s on %s timeframe...", item.Symbol, item.Timeframe))
+		}
+
+		wg.Add(1)
+		go func(i int, item WatchItem) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			callAuth := *auth
+			results[i], errs[i] = getTradeSignal(item.Symbol, item.Timeframe, item.Provider, &callAuth)
+		}(i, item)
+	}
+	wg.Wait()
+
+	var signals []*TradeSignal
+	var failed []string
+	credits := 0
+	for i, item := range items {
+		if errs[i] != nil {
+			logRequestError(item.Symbol, errs[i])
+			failed = append(failed, item.Symbol)
+			continue
+		}
+		credits += results[i].CreditsUsed
+		if filter == "" || strings.EqualFold(results[i].Direction, filter) {
+			signals = append(signals, results[i])
+		}
+	}
+
+	sendSignalAlerts(signals, alert, auth)
+
+	if jsonMode {
+		emitJSON(signals)
+		return
+	}
+
+	if len(signals) > 0 {
+		if full {
+			for _, signal := range signals {
+				printDivider()
+				displaySignal(signal)
+			}
+		} else {
+			printDivider()
+			displaySignalTable(signals)
+		}
+	} else if filter != "" && len(failed) < len(items) {
+		printDivider()
+		logInfo(fmt.Sprintf("No %s signals", filter))
+	}
+
+	printDivider()
+	if len(failed) > 0 {
+		logWarning(fmt.Sprintf("Failed: %s", strings.Join(failed, ", ")))
+	}
+	logInfo(fmt.Sprintf("Credits used: %d", credits))
+	logWarning("⚠ This is AI analysis, NOT financial advice. Trade at your own risk.")
+}
+
+// displaySignalTable prints one line per signal for side-by-side comparison.
+// Confidence below 50 is highlighted in yellow
+func displaySignalTable(signals []*TradeSignal) {
+	fmt.Printf("%s%-10s %-9s %-12s %-12s %-12s %-7s %s%s\n", bold,
+		"SYMBOL", "DIRECTION", "ENTRY", "TP", "SL", "R:R", "CONFIDENCE", reset)
+	for _, signal := range signals {
+		directionColor := green
+		switch signal.Direction {
+		case "SELL":
+			directionColor = red
+		case "HOLD":
+			directionColor = yellow
+		}
+		confidenceColor := ""
+		if signal.Confidence < 50 {
+			confidenceColor = yellow
+		}
+		fmt.Printf("%-10s %s%-9s%s %-12s %-12s %-12s %-7s %s%d%%%s\n",
+			signal.Pair, directionColor, signal.Direction, reset,
+			signal.formatPrice(signal.EntryPrice), signal.formatPrice(signal.TakeProfit), signal.formatPrice(signal.StopLoss),
+			fmt.Sprintf("1:%.2f", signal.RiskReward), confidenceColor, signal.Confidence, reset)
+	}
+}
+
+// Timeframes the signal service analyzes; M is minutes
+var knownTimeframes = []string{"1M", "5M", "15M", "30M", "1H", "4H", "1D", "1W"}
+
+// Spelled-out and bare-unit timeframes
+var timeframeAliases = map[string]string{
+	"H":      "1H",
+	"HOURLY": "1H",
+	"D":      "1D",
+	"DAILY":  "1D",
+	"DAY":    "1D",
+	"W":      "1W",
+	"WEEKLY": "1W",
+	"WEEK":   "1W",
+}
+
+// Long unit names, longest first so HOURS is tried before HOUR
+var timeframeUnits = []struct{ suffix, unit string }{
+	{"MINUTES", "M"}, {"MINUTE", "M"}, {"MINS", "M"}, {"MIN", "M"},
+	{"HOURS", "H"}, {"HOUR", "H"}, {"HRS", "H"}, {"HR", "H"},
+	{"DAYS", "D"}, {"DAY", "D"},
+	{"WEEKS", "W"}, {"WEEK", "W"},
+}
+
+// normalizeTimeframe turns the ways traders write a timeframe (4h, H4,
+// 4hour, daily) into the canonical form, such as 4H or 1D, and rejects
+// anything not in knownTimeframes
+func normalizeTimeframe(value string) (string, error) {
+	tf := strings.ToUpper(strings.TrimSpace(value))
+	if alias, ok := timeframeAliases[tf]; ok {
+		tf = alias
+	}
+	for _, u := range timeframeUnits {
+		if strings.HasSuffix(tf, u.suffix) && len(tf) > len(u.suffix) {
+			tf = strings.TrimSuffix(tf, u.suffix) + u.unit
+			break
+		}
+	}
+	// MetaTrader order: unit first (H4, M15, D1)
+	if len(tf) >= 2 && strings.ContainsRune("MHDW", rune(tf[0])) {
+		if _, err := strconv.Atoi(tf[1:]); err == nil {
+			tf = tf[1:] + tf[:1]
+		}
+	}
+
+	if containsString(knownTimeframes, tf) {
+		return tf, nil
+	}
+	return "", fmt.Errorf("unknown timeframe %s (use %s, or aliases such as 4h, H4, daily)", value, strings.Join(knownTimeframes, ", "))
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// GET TRADE SIGNAL (calls edge function)
 // ═══════════════════════════════════════════════════════════════════════════
 
-func getForexSignal(pair, timeframe string, auth *AuthData) (*ForexSignal, error) {
+func getTradeSignal(pair, timeframe, provider string, auth *AuthData) (*TradeSignal, error) {
 	payload := map[string]interface{}{
 		"pair":      pair,
 		"timeframe": timeframe,
 	}
+	if provider != "" {
+		payload["provider"] = provider
+	}
 
 	jsonData, _ := json.Marshal(payload)
-	resp, err := makeAuthenticatedRequest(
+	resp, err := makeAuthenticatedRequestWithRetry(
 		"POST",
-		EndpointSignal,
+		apiEndpoint(EndpointSignal),
 		bytes.NewBuffer(jsonData),
 		auth,
 	)
@@ -104,18 +358,28 @@ func getForexSignal(pair, timeframe string, auth *AuthData) (*ForexSignal, error
 	defer resp.Body.Close()
 
 	if resp.StatusCode == 402 {
-		return nil, fmt.Errorf("insufficient credits")
+		return nil, errInsufficientCredits
 	}
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("server error: %s", string(body))
+		return nil, fmt.Errorf("server error: %s", redactBody(body))
 	}
 
-	var signal ForexSignal
+	var signal TradeSignal
 	if err := json.NewDecoder(resp.Body).Decode(&signal); err != nil {
 		return nil, err
 	}
+	if signal.Provider == "" {
+		signal.Provider = provider
+	}
+	if signal.AssetClass == "" {
+		signal.AssetClass = assetClassOf(signal.Pair)
+	}
+
+	if err := recordSignal(&signal); err != nil {
+		logWarning(fmt.Sprintf("Failed to save signal history: %v", err))
+	}
 
 	return &signal, nil
 }
@@ -124,7 +388,7 @@ func getForexSignal(pair, timeframe string, auth *AuthData) (*ForexSignal, error
 // DISPLAY SIGNAL (beautiful terminal output)
 // ═══════════════════════════════════════════════════════════════════════════
 
-func displaySignal(signal *ForexSignal) {
+func displaySignal(signal *TradeSignal) {
 	fmt.Println()
 	
 	// Header with direction
@@ -146,14 +410,17 @@ func displaySignal(signal *ForexSignal) {
 	fmt.Println()
 
 	// Price levels
-	logInfo(fmt.Sprintf("Entry Price:  %.5f", signal.EntryPrice))
-	fmt.Printf("%s%sTP (Target):   %.5f%s (+%.1f pips)\n", bold, green, signal.TakeProfit, reset, signal.TPPips)
-	fmt.Printf("%s%sSL (Stop):     %.5f%s (-%.1f pips)\n", bold, red, signal.StopLoss, reset, signal.SLPips)
+	logInfo(fmt.Sprintf("Entry Price:  %s", signal.formatPrice(signal.EntryPrice)))
+	fmt.Printf("%s%sTP (Target):   %s%s (+%s)\n", bold, green, signal.formatPrice(signal.TakeProfit), reset, signal.formatDistance(signal.TakeProfit, signal.TPPips))
+	fmt.Printf("%s%sSL (Stop):     %s%s (-%s)\n", bold, red, signal.formatPrice(signal.StopLoss), reset, signal.formatDistance(signal.StopLoss, signal.SLPips))
 	fmt.Println()
 
 	// Risk/Reward & Confidence
 	logInfo(fmt.Sprintf("Risk/Reward:  1:%.2f", signal.RiskReward))
 	logInfo(fmt.Sprintf("Timeframe:    %s", signal.Timeframe))
+	if signal.BacktestID != "" {
+		logInfo(fmt.Sprintf("Backtest:     %s", signal.BacktestID))
+	}
 	
 	confidenceColor := green
 	if signal.Confidence < 60 {
@@ -200,20 +467,105 @@ func displaySignal(signal *ForexSignal) {
 // TYPES
 // ═══════════════════════════════════════════════════════════════════════════
 
-type ForexSignal struct {
-	Pair        string   `json:"pair"`         // e.g., "EURUSD"
-	Direction   string   `json:"direction"`    // "BUY", "SELL", "HOLD"
-	EntryPrice  float64  `json:"entry_price"`  // Recommended entry
-	TakeProfit  float64  `json:"take_profit"`  // TP level
-	StopLoss    float64  `json:"stop_loss"`    // SL level
-	TPPips      float64  `json:"tp_pips"`      // TP in pips
-	SLPips      float64  `json:"sl_pips"`      // SL in pips
-	RiskReward  float64  `json:"risk_reward"`  // R:R ratio
-	Timeframe   string   `json:"timeframe"`    // e.g., "4H"
-	Confidence  int      `json:"confidence"`   // 0-100%
-	Analysis    string   `json:"analysis"`     // Detailed market analysis
-	KeyFactors  []string `json:"key_factors"`  // Bullet points of key factors
-	Warnings    []string `json:"warnings"`     // Risk warnings
-	TradePlan   string   `json:"trade_plan"`   // Step-by-step plan
-	CreditsUsed int      `json:"credits_used"` // Credits consumed
-}
\ No newline at end of file
+// TradeSignal - one prediction, for any asset class. Forex prices are
+// shown to 5 decimals with distances in pips; stock and crypto prices in
+// dollars
+type TradeSignal struct {
+	Pair        string   `json:"pair"`                  // e.g., "EURUSD", "AAPL", "BTCUSD"
+	AssetClass  string   `json:"asset_class,omitempty"` // forex, stock or crypto
+	Direction   string   `json:"direction"`             // "BUY", "SELL", "HOLD"
+	EntryPrice  float64  `json:"entry_price"`           // Recommended entry
+	TakeProfit  float64  `json:"take_profit"`           // TP level
+	StopLoss    float64  `json:"stop_loss"`             // SL level
+	TPPips      float64  `json:"tp_pips"`               // TP in pips (forex only)
+	SLPips      float64  `json:"sl_pips"`               // SL in pips (forex only)
+	RiskReward  float64  `json:"risk_reward"`           // R:R ratio
+	Timeframe   string   `json:"timeframe"`             // e.g., "4H"
+	Confidence  int      `json:"confidence"`            // 0-100%
+	Analysis    string   `json:"analysis"`              // Detailed market analysis
+	KeyFactors  []string `json:"key_factors"`           // Bullet points of key factors
+	Warnings    []string `json:"warnings"`              // Risk warnings
+	TradePlan   string   `json:"trade_plan"`            // Step-by-step plan
+	CreditsUsed int      `json:"credits_used"`          // Credits consumed
+	Provider    string   `json:"provider,omitempty"`    // AI provider that produced it
+	BacktestID  string   `json:"backtest_id,omitempty"` // backtest set the model was validated on
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// ASSET CLASSES
+// ═══════════════════════════════════════════════════════════════════════════
+
+// ISO codes that make a six-letter symbol a currency pair; XAU and XAG are
+// quoted like pairs too
+var currencyCodes = []string{"USD", "EUR", "GBP", "JPY", "CHF", "AUD", "NZD", "CAD", "SEK", "NOK", "DKK", "SGD", "HKD", "ZAR", "MXN", "TRY", "PLN", "CNH", "XAU", "XAG"}
+
+var cryptoCodes = []string{"BTC", "ETH", "SOL", "XRP", "ADA", "DOGE", "BNB", "LTC", "DOT", "AVAX", "LINK", "MATIC"}
+
+// assetClassOf guesses the asset class of a symbol the server did not label
+func assetClassOf(symbol string) string {
+	symbol = strings.ToUpper(symbol)
+	for _, code := range cryptoCodes {
+		if strings.HasPrefix(symbol, code) && len(symbol) > len(code) {
+			return "crypto"
+		}
+	}
+	if len(symbol) == 6 && containsString(currencyCodes, symbol[:3]) && containsString(currencyCodes, symbol[3:]) {
+		return "forex"
+	}
+	return "stock"
+}
+
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9.-]*$`)
+
+// validateSymbol checks symbol against its asset class: a forex pair is two
+// currency codes (EURUSD), a crypto pair a coin and its quote (BTCUSD), and
+// anything else a stock ticker of letters, digits, dots or dashes (AAPL, BRK.B)
+func validateSymbol(symbol string) error {
+	symbol = strings.ToUpper(symbol)
+	if len(symbol) > 12 || !symbolPattern.MatchString(symbol) {
+		return fmt.Errorf("Invalid symbol %q. Examples: EURUSD or XAUUSD (forex), AAPL or SPY (stock), BTCUSD (crypto)", symbol)
+	}
+	return nil
+}
+
+func (s *TradeSignal) assetClass() string {
+	if s.AssetClass != "" {
+		return s.AssetClass
+	}
+	return assetClassOf(s.Pair)
+}
+
+// pipSize is 0.01 for JPY pairs and 0.0001 for the rest
+func (s *TradeSignal) pipSize() float64 {
+	if strings.HasSuffix(strings.ToUpper(s.Pair), "JPY") {
+		return 0.01
+	}
+	return 0.0001
+}
+
+// formatPrice prints forex to pip precision plus one digit, and stocks and
+// crypto in dollars, with more digits for coins worth under a dollar
+func (s *TradeSignal) formatPrice(price float64) string {
+	switch {
+	case s.assetClass() == "forex" && s.pipSize() == 0.01:
+		return fmt.Sprintf("%.3f", price)
+	case s.assetClass() == "forex":
+		return fmt.Sprintf("%.5f", price)
+	case s.EntryPrice != 0 && math.Abs(s.EntryPrice) < 1:
+		return fmt.Sprintf("$%.6f", price)
+	}
+	return fmt.Sprintf("$%.2f", price)
+}
+
+// formatDistance describes how far level is from the entry: in pips for
+// forex, taken from the server when it sent them, and in dollars otherwise
+func (s *TradeSignal) formatDistance(level, pips float64) string {
+	distance := math.Abs(level - s.EntryPrice)
+	if s.assetClass() == "forex" {
+		if pips == 0 {
+			pips = distance / s.pipSize()
+		}
+		return fmt.Sprintf("%.1f pips", math.Abs(pips))
+	}
+	return s.formatPrice(distance)
+}
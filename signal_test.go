@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateSymbol(t *testing.T) {
+	tests := []struct {
+		symbol string
+		valid  bool
+	}{
+		{"EURUSD", true},
+		{"xauusd", true},
+		{"BTCUSD", true},
+		{"SPY", true},
+		{"QQQ", true},
+		{"AAPL", true},
+		{"BRK.B", true},
+		{"", false},
+		{"EUR/USD", false},
+		{"A B", false},
+		{".SPY", false},
+		{strings.Repeat("A", 13), false},
+	}
+	for _, tt := range tests {
+		err := validateSymbol(tt.symbol)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateSymbol(%q) error = %v, want valid %v", tt.symbol, err, tt.valid)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── SIGNAL HISTORY ──────────────────────────────────────────────────────────
+// Every signal is appended to ~/.keke/signals.jsonl so past predictions can
+// be compared with what the market actually did
+
+// SignalRecord - one line of signals.jsonl
+type SignalRecord struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Outcome   string    `json:"outcome,omitempty"` // "win" or "loss", set with --mark-outcome
+	TradeSignal
+}
+
+// Batches fetch signals concurrently; appends go one at a time
+var signalHistoryMu sync.Mutex
+
+// recordSignal appends signal to the history file
+func recordSignal(signal *TradeSignal) error {
+	signalHistoryMu.Lock()
+	defer signalHistoryMu.Unlock()
+
+	if err := os.MkdirAll(globalDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(SignalRecord{ID: newSignalID(), Timestamp: time.Now(), TradeSignal: *signal})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(globalSignalHistoryFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// newSignalID returns a short id to refer to a signal on the command line
+func newSignalID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// readSignalHistory returns recorded signals, oldest first. Unreadable lines
+// are skipped
+func readSignalHistory() ([]SignalRecord, error) {
+	f, err := os.Open(globalSignalHistoryFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []SignalRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var record SignalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+		// Records saved before ids existed get a stable one from their time
+		if record.ID == "" {
+			record.ID = fmt.Sprintf("%06x", record.Timestamp.UnixNano()&0xffffff)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func writeSignalHistory(records []SignalRecord) error {
+	var b strings.Builder
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+	return os.WriteFile(globalSignalHistoryFile(), []byte(b.String()), 0600)
+}
+
+func handleSignalHistory(args []string) {
+	symbol := ""
+	limit := 20
+	clearHistory := false
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--mark-outcome":
+			if i+2 >= len(args) {
+				logError("Usage: keke signal history --mark-outcome <id> win|loss")
+				return
+			}
+			markSignalOutcome(args[i+1], strings.ToLower(args[i+2]))
+			return
+		case "--limit":
+			if i+1 >= len(args) {
+				logError("--limit needs a number")
+				return
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				logError(fmt.Sprintf("Invalid --limit: %s", args[i+1]))
+				return
+			}
+			limit = n
+			i++
+		case "--clear":
+			clearHistory = true
+		default:
+			symbol = strings.ToUpper(args[i])
+		}
+	}
+
+	records, err := readSignalHistory()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read signal history: %v", err))
+		return
+	}
+
+	if clearHistory {
+		clearSignalHistory(records, symbol)
+		return
+	}
+
+	// Newest first, filtered by symbol
+	var matches []SignalRecord
+	for i := len(records) - 1; i >= 0 && len(matches) < limit; i-- {
+		if symbol == "" || records[i].Pair == symbol {
+			matches = append(matches, records[i])
+		}
+	}
+
+	if jsonMode {
+		emitJSON(matches)
+		return
+	}
+
+	if len(matches) == 0 {
+		logInfo("No signals recorded yet")
+		return
+	}
+
+	printDivider()
+	fmt.Printf("%s%-6s %-16s %-10s %-4s %-5s %-5s %-12s %-12s %-12s %s%s\n",
+		bold, "ID", "TIME", "SYMBOL", "TF", "DIR", "CONF", "ENTRY", "TP", "SL", "OUTCOME", reset)
+	wins, losses := 0, 0
+	for _, r := range matches {
+		color := ""
+		if r.Confidence < 50 {
+			color = yellow
+		}
+		outcome := "-"
+		switch r.Outcome {
+		case "win":
+			outcome = green + "win" + reset
+			wins++
+		case "loss":
+			outcome = red + "loss" + reset
+			losses++
+		}
+		fmt.Printf("%s%-6s %-16s %-10s %-4s %-5s %-5s %-12s %-12s %-12s%s %s\n",
+			color, r.ID, r.Timestamp.Local().Format("2006-01-02 15:04"), r.Pair, r.Timeframe, r.Direction,
+			fmt.Sprintf("%d%%", r.Confidence), r.formatPrice(r.EntryPrice), r.formatPrice(r.TakeProfit), r.formatPrice(r.StopLoss), reset, outcome)
+	}
+	printDivider()
+	if wins+losses > 0 {
+		logInfo(fmt.Sprintf("Accuracy: %d%% (%d wins, %d losses)", wins*100/(wins+losses), wins, losses))
+	}
+	logInfo(fmt.Sprintf("Showing %d of %d recorded signals (%s)", len(matches), len(records), globalSignalHistoryFile()))
+	logInfo("Record a result with: keke signal history --mark-outcome <id> win|loss")
+}
+
+// markSignalOutcome records whether the signal with id turned out right
+func markSignalOutcome(id, outcome string) {
+	if outcome != "win" && outcome != "loss" {
+		logError(fmt.Sprintf("Invalid outcome %s (use win or loss)", outcome))
+		return
+	}
+
+	signalHistoryMu.Lock()
+	defer signalHistoryMu.Unlock()
+
+	records, err := readSignalHistory()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read signal history: %v", err))
+		return
+	}
+
+	for i := range records {
+		if records[i].ID != id {
+			continue
+		}
+		records[i].Outcome = outcome
+		if err := writeSignalHistory(records); err != nil {
+			logError(fmt.Sprintf("Failed to save signal history: %v", err))
+			return
+		}
+		logSuccess(fmt.Sprintf("Marked %s %s %s as %s", id, records[i].Pair, records[i].Direction, outcome))
+		return
+	}
+	logError(fmt.Sprintf("No signal with id %s (see 'keke signal history')", id))
+}
+
+// clearSignalHistory deletes every record, or only those for symbol
+func clearSignalHistory(records []SignalRecord, symbol string) {
+	what := "all signal history"
+	if symbol != "" {
+		what = fmt.Sprintf("signal history for %s", symbol)
+	}
+	if !promptYesNo(fmt.Sprintf("Delete %s? (y/n)", what)) {
+		logInfo("Cancelled")
+		return
+	}
+
+	var kept []SignalRecord
+	if symbol != "" {
+		for _, record := range records {
+			if record.Pair != symbol {
+				kept = append(kept, record)
+			}
+		}
+	}
+
+	var err error
+	if len(kept) == 0 {
+		err = os.Remove(globalSignalHistoryFile())
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	} else {
+		err = writeSignalHistory(kept)
+	}
+	if err != nil {
+		logError(fmt.Sprintf("Failed to clear signal history: %v", err))
+		return
+	}
+	logSuccess(fmt.Sprintf("Deleted %d signals", len(records)-len(kept)))
+}
@@ -0,0 +1,581 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─── SNAPSHOTS ───────────────────────────────────────────────────────────────
+// Inspect backups without restoring anything (CLI-only, no AI involved)
+
+func handleSnapshots(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	if len(args) > 0 && args[0] == "prune" {
+		handleSnapshotsPrune(args[1:])
+		return
+	}
+
+	// Parse flags
+	var target string
+	diffIndex := 0
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--diff" && i+1 < len(args):
+			fmt.Sscanf(args[i+1], "%d", &diffIndex)
+			if diffIndex < 1 {
+				logError(fmt.Sprintf("Invalid snapshot number: %s", args[i+1]))
+				return
+			}
+			i++
+		case strings.HasPrefix(args[i], "--"):
+			logError(fmt.Sprintf("Unknown flag: %s", args[i]))
+			return
+		default:
+			target = args[i]
+		}
+	}
+
+	snapshots, err := loadSnapshots()
+	if err != nil || len(snapshots) == 0 {
+		if jsonMode && diffIndex == 0 {
+			emitJSON([]SnapshotInfo{})
+			return
+		}
+		logInfo("No snapshots available")
+		return
+	}
+
+	if target != "" {
+		snaps, ok := snapshots[snapshotKey(target)]
+		if !ok {
+			logError(fmt.Sprintf("No snapshots found for: %s", target))
+			return
+		}
+		snapshots = map[string][]SnapshotInfo{snapshotKey(target): snaps}
+	}
+
+	// --diff N compares snapshot N of the given file with its current content
+	if diffIndex > 0 {
+		if target == "" {
+			logError("Usage: keke snapshots <file> --diff N")
+			return
+		}
+		snaps := snapshots[snapshotKey(target)]
+		if diffIndex > len(snaps) {
+			logError(fmt.Sprintf("Invalid snapshot number: %d (%s has %d)", diffIndex, target, len(snaps)))
+			return
+		}
+		current, err := os.ReadFile(target)
+		if err != nil {
+			logError(fmt.Sprintf("Failed to read %s: %v", target, err))
+			return
+		}
+		showSnapshotDiff(target, current, snaps[diffIndex-1])
+		return
+	}
+
+	if jsonMode {
+		emitJSON(sortedSnapshots(snapshots))
+		return
+	}
+
+	// Stable order by file name
+	var names []string
+	for name := range snapshots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	printDivider()
+	for _, name := range names {
+		snaps := snapshots[name]
+		var total int64
+		for _, snap := range snaps {
+			total += snap.Size
+		}
+
+		fmt.Printf("%s%s%s %s(%d snapshots, %s)%s\n", bold, name, reset, dim, len(snaps), formatBytes(total), reset)
+		for i, snap := range snaps {
+			label := ""
+			if snap.Name != "" {
+				label = fmt.Sprintf(" [%s]", snap.Name)
+			}
+			fmt.Printf("  %d. %s%s  %s%s%s\n", i+1, formatSnapshotTime(snap.Timestamp), label, dim, formatBytes(snap.Size), reset)
+		}
+		fmt.Println()
+	}
+	printDivider()
+	logInfo("Diff one against the current file: keke snapshots <file> --diff N")
+}
+
+// ─── NAMED SNAPSHOTS ─────────────────────────────────────────────────────────
+// keke snapshot save|restore|list|delete — labelled backups stored next to
+// the timestamped ones as <path>.<name>.snap.gz, and restored to <path>
+
+func handleSnapshot(args []string) {
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	if len(args) == 0 {
+		printSnapshotUsage()
+		return
+	}
+
+	switch args[0] {
+	case "save":
+		handleSnapshotSave(args[1:])
+	case "restore":
+		handleSnapshotRestore(args[1:])
+	case "list":
+		handleSnapshotList()
+	case "delete":
+		handleSnapshotDelete(args[1:])
+	default:
+		logError(fmt.Sprintf("Unknown subcommand: %s", args[0]))
+		printSnapshotUsage()
+	}
+}
+
+func printSnapshotUsage() {
+	logError("Usage: keke snapshot <save|restore|list|delete>")
+	logInfo("Examples:")
+	logInfo("  keke snapshot save before-auth auth.go config.go")
+	logInfo("  keke snapshot restore before-auth")
+	logInfo("  keke snapshot list")
+	logInfo("  keke snapshot delete before-auth")
+}
+
+func handleSnapshotSave(rest []string) {
+	force := forceFlag // --force is a global flag
+
+	if len(rest) < 2 {
+		logError("Usage: keke snapshot save <name> <file>... [--force]")
+		return
+	}
+
+	name, files := rest[0], rest[1:]
+	if err := validateSnapshotName(name); err != nil {
+		logError(err.Error())
+		return
+	}
+
+	// Check every file first so a collision doesn't leave a partial save
+	seen := map[string]string{}
+	for _, file := range files {
+		key := snapshotKey(file)
+		if previous, ok := seen[key]; ok {
+			logError(fmt.Sprintf("%s and %s are the same file", previous, file))
+			return
+		}
+		seen[key] = file
+
+		if info, err := os.Stat(file); err != nil {
+			logError(fmt.Sprintf("Cannot snapshot %s: %v", file, err))
+			return
+		} else if info.IsDir() {
+			logError(fmt.Sprintf("Cannot snapshot %s: is a directory", file))
+			return
+		}
+		if snaps := findNamedSnapshot(file, name); len(snaps) > 0 && !force {
+			logError(fmt.Sprintf("Snapshot '%s' already exists for %s (use --force to overwrite)", name, file))
+			return
+		}
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			logError(fmt.Sprintf("Failed to read %s: %v", file, err))
+			return
+		}
+		// --force replaces an older uncompressed copy too
+		for _, old := range findNamedSnapshot(file, name) {
+			os.Remove(old.Path)
+		}
+		path := namedSnapshotPath(file, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			logError(fmt.Sprintf("Failed to save snapshot of %s: %v", file, err))
+			return
+		}
+		if err := writeSnapshotFile(path, content); err != nil {
+			logError(fmt.Sprintf("Failed to save snapshot of %s: %v", file, err))
+			return
+		}
+		logSuccess(fmt.Sprintf("Saved: %s [%s]", file, name))
+	}
+}
+
+func handleSnapshotRestore(args []string) {
+	if len(args) == 0 {
+		logError("Usage: keke snapshot restore <name>")
+		return
+	}
+
+	name := args[0]
+	matches := findNamedSnapshots(name)
+	if len(matches) == 0 {
+		logError(fmt.Sprintf("No snapshot named '%s'", name))
+		return
+	}
+
+	printDivider()
+	logInfo(fmt.Sprintf("Snapshot '%s' will restore:", name))
+	for _, snap := range matches {
+		fmt.Printf("  • %s\n", snap.OriginalFile)
+	}
+	printDivider()
+
+	if !promptYesNo("This will OVERWRITE current versions. Continue? (y/n)") {
+		logInfo("Cancelled")
+		return
+	}
+
+	// restoreSnapshot keeps the current version as an automatic snapshot
+	for _, snap := range matches {
+		if err := restoreSnapshot(snap); err != nil {
+			logError(err.Error())
+			continue
+		}
+		logSuccess(fmt.Sprintf("Restored: %s", snap.OriginalFile))
+	}
+}
+
+func handleSnapshotList() {
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		logInfo("No snapshots available")
+		return
+	}
+
+	byName := make(map[string][]SnapshotInfo)
+	for _, snaps := range snapshots {
+		for _, snap := range snaps {
+			if snap.Name != "" {
+				byName[snap.Name] = append(byName[snap.Name], snap)
+			}
+		}
+	}
+
+	var names []string
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if jsonMode {
+		list := []map[string]interface{}{}
+		for _, name := range names {
+			list = append(list, map[string]interface{}{
+				"name":      name,
+				"timestamp": byName[name][0].Timestamp,
+				"snapshots": byName[name],
+			})
+		}
+		emitJSON(list)
+		return
+	}
+
+	if len(byName) == 0 {
+		logInfo("No named snapshots. Create one with: keke snapshot save <name> <file>")
+		return
+	}
+
+	printDivider()
+	for _, name := range names {
+		snaps := byName[name]
+		fmt.Printf("%s%s%s %s(%s)%s\n", bold, name, reset, dim, formatSnapshotTime(snaps[0].Timestamp), reset)
+		for _, snap := range snaps {
+			fmt.Printf("  • %s  %s%s%s\n", snap.OriginalFile, dim, formatBytes(snap.Size), reset)
+		}
+	}
+	printDivider()
+}
+
+func handleSnapshotDelete(args []string) {
+	if len(args) == 0 {
+		logError("Usage: keke snapshot delete <name>")
+		return
+	}
+
+	name := args[0]
+	matches := findNamedSnapshots(name)
+	if len(matches) == 0 {
+		logError(fmt.Sprintf("No snapshot named '%s'", name))
+		return
+	}
+
+	for _, snap := range matches {
+		if err := os.Remove(snap.Path); err != nil {
+			logError(fmt.Sprintf("Failed to delete %s: %v", snap.SnapshotFile, err))
+			continue
+		}
+	}
+
+	logSuccess(fmt.Sprintf("Deleted snapshot '%s' (%d files)", name, len(matches)))
+}
+
+// ─── SNAPSHOT HELPERS ────────────────────────────────────────────────────────
+
+// Timestamp format used in snapshot file names
+const snapshotTimeFormat = "20060102_150405"
+
+// formatSnapshotTime renders a snapshot timestamp for humans, or returns it
+// unchanged if it cannot be parsed
+func formatSnapshotTime(ts string) string {
+	t, err := parseSnapshotTime(ts)
+	if err != nil {
+		return ts
+	}
+	return t.Format("Jan 2, 2006 15:04:05")
+}
+
+// validateSnapshotName rejects names that would break <file>.<name>.snap
+// parsing or be mistaken for a timestamp
+func validateSnapshotName(name string) error {
+	if name == "" {
+		return fmt.Errorf("snapshot name cannot be empty")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("invalid snapshot name '%s': use letters, digits, '-' and '_' only", name)
+		}
+	}
+	if _, err := parseSnapshotTime(name); err == nil {
+		return fmt.Errorf("invalid snapshot name '%s': looks like a timestamp", name)
+	}
+	return nil
+}
+
+func namedSnapshotPath(file, name string) string {
+	return filepath.Join(projectSnapshotsDir(), fmt.Sprintf("%s.%s.snap.gz", filepath.FromSlash(snapshotKey(file)), name))
+}
+
+// findNamedSnapshot returns the snapshot of one file saved under name
+func findNamedSnapshot(file, name string) []SnapshotInfo {
+	var matches []SnapshotInfo
+	for _, snap := range findNamedSnapshots(name) {
+		if snap.OriginalFile == snapshotKey(file) {
+			matches = append(matches, snap)
+		}
+	}
+	return matches
+}
+
+// findNamedSnapshots returns every file's snapshot saved under name
+func findNamedSnapshots(name string) []SnapshotInfo {
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		return nil
+	}
+
+	var matches []SnapshotInfo
+	for _, snaps := range snapshots {
+		for _, snap := range snaps {
+			if snap.Name == name {
+				matches = append(matches, snap)
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].OriginalFile < matches[j].OriginalFile
+	})
+	return matches
+}
+
+// ─── SNAPSHOT FILES ──────────────────────────────────────────────────────────
+// Snapshots are written gzipped as .snap.gz; plain .snap files from older
+// versions are still read as they are
+
+func writeSnapshotFile(path string, content []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(content); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// readSnapshot returns the original content of a snapshot
+func readSnapshot(snap SnapshotInfo) ([]byte, error) {
+	data, err := os.ReadFile(snap.Path)
+	if err != nil || !snap.Compressed {
+		return data, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("corrupt snapshot %s: %v", snap.SnapshotFile, err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+// compressSnapshot rewrites an uncompressed snapshot as .snap.gz, keeping its
+// modification time (named snapshots are dated by it). Returns the new size
+func compressSnapshot(snap SnapshotInfo) (int64, error) {
+	content, err := os.ReadFile(snap.Path)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(snap.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	path := snap.Path + ".gz"
+	if err := writeSnapshotFile(path, content); err != nil {
+		return 0, err
+	}
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	if err := os.Remove(snap.Path); err != nil {
+		return 0, err
+	}
+
+	compressed, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return compressed.Size(), nil
+}
+
+// ─── PRUNE ───────────────────────────────────────────────────────────────────
+// 'keke snapshots prune' deletes automatic snapshots; named ones are kept
+
+func handleSnapshotsPrune(args []string) {
+	keep := -1
+	var olderThan time.Duration
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--keep":
+			if i+1 >= len(args) {
+				logError("--keep needs a number")
+				return
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				logError(fmt.Sprintf("Invalid --keep: %s", args[i+1]))
+				return
+			}
+			keep = n
+			i++
+		case "--older-than":
+			if i+1 >= len(args) {
+				logError("--older-than needs an age like 7d or 12h")
+				return
+			}
+			age, err := parseAge(args[i+1])
+			if err != nil {
+				logError(err.Error())
+				return
+			}
+			olderThan = age
+			i++
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke snapshots prune [--keep N] [--older-than 7d]")
+			return
+		}
+	}
+
+	if keep < 0 && olderThan == 0 {
+		logError("Usage: keke snapshots prune [--keep N] [--older-than 7d]")
+		return
+	}
+
+	snapshots, err := loadSnapshots()
+	if err != nil || len(snapshots) == 0 {
+		logInfo("No snapshots available")
+		return
+	}
+
+	var stale []SnapshotInfo
+	for _, snaps := range snapshots {
+		stale = append(stale, selectSnapshotsToPrune(snaps, keep, olderThan)...)
+	}
+
+	if len(stale) == 0 {
+		logInfo("Nothing to prune")
+		return
+	}
+
+	if dryRun {
+		for _, snap := range stale {
+			logInfo(fmt.Sprintf("[DRY RUN] Would delete %s", snap.SnapshotFile))
+		}
+		return
+	}
+
+	count, size := deleteSnapshots(stale)
+	logSuccess(fmt.Sprintf("Pruned %d snapshots, freed %s", count, formatBytes(size)))
+}
+
+// selectSnapshotsToPrune picks the automatic snapshots of one file (newest
+// first) beyond the keep newest that are also older than olderThan. A
+// negative keep or zero olderThan disables that condition
+func selectSnapshotsToPrune(snaps []SnapshotInfo, keep int, olderThan time.Duration) []SnapshotInfo {
+	var stale []SnapshotInfo
+	kept := 0
+	for _, snap := range snaps {
+		if snap.Name != "" {
+			continue
+		}
+		if keep >= 0 && kept < keep {
+			kept++
+			continue
+		}
+		if olderThan > 0 {
+			taken, err := parseSnapshotTime(snap.Timestamp)
+			if err != nil || time.Since(taken) < olderThan {
+				continue
+			}
+		}
+		stale = append(stale, snap)
+	}
+	return stale
+}
+
+// deleteSnapshots removes snapshot files and reports how many bytes were freed
+func deleteSnapshots(snaps []SnapshotInfo) (int, int64) {
+	count, size := 0, int64(0)
+	for _, snap := range snaps {
+		if err := os.Remove(snap.Path); err != nil {
+			logWarning(fmt.Sprintf("Failed to delete %s: %v", snap.SnapshotFile, err))
+			continue
+		}
+		count++
+		size += snap.Size
+	}
+	return count, size
+}
+
+// parseAge accepts "7d", "12h", "30m" or a plain number of days
+func parseAge(value string) (time.Duration, error) {
+	if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days > 0 {
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid age %s (use e.g. 7d or 12h)", value)
+}
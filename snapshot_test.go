@@ -0,0 +1,133 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestProject runs the test inside an initialized project in a temp
+// directory, with its own HOME and no prompts
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(old) })
+	t.Setenv("HOME", t.TempDir())
+	loadedConfig = nil
+	t.Cleanup(func() { loadedConfig = nil })
+	assumeYes = true
+	t.Cleanup(func() { assumeYes = false })
+
+	if err := os.MkdirAll(projectSnapshotsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNamedSnapshotRoundTrip(t *testing.T) {
+	newTestProject(t)
+	writeTestFile(t, "src/auth.go", "original")
+
+	handleSnapshotSave([]string{"before-auth", "src/auth.go"})
+	writeTestFile(t, "src/auth.go", "edited")
+	handleSnapshotRestore([]string{"before-auth"})
+
+	if got := readTestFile(t, "src/auth.go"); got != "original" {
+		t.Errorf("src/auth.go = %q after restore, want %q", got, "original")
+	}
+	if _, err := os.Stat("auth.go"); !os.IsNotExist(err) {
+		t.Errorf("restore wrote ./auth.go instead of src/auth.go")
+	}
+
+	// The edited version was snapshotted before being overwritten
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var automatic []SnapshotInfo
+	for _, snap := range snapshots["src/auth.go"] {
+		if snap.Name == "" {
+			automatic = append(automatic, snap)
+		}
+	}
+	if len(automatic) != 1 {
+		t.Fatalf("got %d automatic snapshots of src/auth.go, want 1", len(automatic))
+	}
+	if content, _ := readSnapshot(automatic[0]); string(content) != "edited" {
+		t.Errorf("automatic snapshot holds %q, want %q", content, "edited")
+	}
+}
+
+func TestNamedSnapshotSameBaseName(t *testing.T) {
+	newTestProject(t)
+	writeTestFile(t, "a/main.go", "package a")
+	writeTestFile(t, "b/main.go", "package b")
+
+	handleSnapshotSave([]string{"x", "a/main.go", "b/main.go"})
+	if got := len(findNamedSnapshots("x")); got != 2 {
+		t.Fatalf("saved %d snapshots, want 2", got)
+	}
+
+	writeTestFile(t, "a/main.go", "changed")
+	writeTestFile(t, "b/main.go", "changed")
+	handleSnapshotRestore([]string{"x"})
+
+	if got := readTestFile(t, "a/main.go"); got != "package a" {
+		t.Errorf("a/main.go = %q, want %q", got, "package a")
+	}
+	if got := readTestFile(t, "b/main.go"); got != "package b" {
+		t.Errorf("b/main.go = %q, want %q", got, "package b")
+	}
+}
+
+func TestNamedSnapshotRejectsSameFileTwice(t *testing.T) {
+	newTestProject(t)
+	writeTestFile(t, "main.go", "package main")
+
+	handleSnapshotSave([]string{"x", "main.go", "./main.go"})
+	if got := len(findNamedSnapshots("x")); got != 0 {
+		t.Errorf("saved %d snapshots, want none", got)
+	}
+}
+
+func TestNamedSnapshotExistingNeedsForce(t *testing.T) {
+	newTestProject(t)
+	writeTestFile(t, "main.go", "v1")
+	handleSnapshotSave([]string{"x", "main.go"})
+
+	writeTestFile(t, "main.go", "v2")
+	handleSnapshotSave([]string{"x", "main.go"})
+	snaps := findNamedSnapshot("main.go", "x")
+	if len(snaps) != 1 {
+		t.Fatalf("got %d snapshots named x, want 1", len(snaps))
+	}
+	if content, _ := readSnapshot(snaps[0]); string(content) != "v1" {
+		t.Errorf("snapshot x holds %q without --force, want %q", content, "v1")
+	}
+}
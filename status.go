@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ─── STATUS ──────────────────────────────────────────────────────────────────
+// One-stop summary of project, account, session and credit state
+
+func handleStatus() {
+	printDivider()
+
+	// Project
+	if isProjectInitialized() {
+		logSuccess(fmt.Sprintf("Project:      initialized (%s)", projectDir()))
+	} else {
+		logWarning("Project:      not initialized (run 'keke init')")
+	}
+
+	// Account
+	auth, err := readAuth()
+	if err != nil {
+		logWarning("Account:      not logged in (run 'keke login')")
+	} else {
+		logInfo(fmt.Sprintf("Account:      %s (%s)", auth.Email, auth.Plan))
+	}
+
+	if isProjectInitialized() {
+		// Session
+		if session, err := loadSession(""); err == nil {
+			state := "expired"
+			if hasActiveSession() {
+				state = "active"
+			}
+			logInfo(fmt.Sprintf("Session:      %s (%s, %s mode, updated %s ago)",
+				state, session.ID, session.Mode, formatAge(time.Since(session.UpdatedAt))))
+			logInfo(fmt.Sprintf("Last prompt:  %s", truncateText(session.LastPrompt, 60)))
+		} else {
+			logInfo("Session:      none")
+		}
+
+		// Permissions
+		perms, _ := readPermissions()
+		logInfo(fmt.Sprintf("Permissions:  read %d  write %d  execute %d grants (keke permissions list)",
+			len(perms.Read), len(perms.Write), len(perms.Execute)))
+
+		// Most recent snapshot
+		var latest *SnapshotInfo
+		if snapshots, err := loadSnapshots(); err == nil {
+			for _, snaps := range snapshots {
+				if len(snaps) > 0 && (latest == nil || snaps[0].Timestamp > latest.Timestamp) {
+					snap := snaps[0]
+					latest = &snap
+				}
+			}
+		}
+		if latest != nil {
+			logInfo(fmt.Sprintf("Last snapshot: %s (%s)", latest.OriginalFile, formatSnapshotTime(latest.Timestamp)))
+		} else {
+			logInfo("Last snapshot: none")
+		}
+	}
+
+	// Credits - live if possible, cached when offline
+	if auth != nil {
+		if credits, err := fetchCredits(auth); err == nil {
+			logInfo(fmt.Sprintf("Credits:      %d / %d", credits.Remaining, credits.MonthlyLimit))
+		} else if cached, cacheErr := readCachedCredits(); cacheErr == nil {
+			logInfo(fmt.Sprintf("Credits:      %d / %d (cached %s ago)",
+				cached.Remaining, cached.MonthlyLimit, formatAge(time.Since(cached.FetchedAt))))
+		} else {
+			logWarning(fmt.Sprintf("Credits:      unavailable (%v)", err))
+		}
+	}
+
+	printDivider()
+}
+
+// formatAge renders a duration as a short "5m" / "2h" / "3d" string
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// truncateText shortens s to max runes, adding "..." when cut
+func truncateText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
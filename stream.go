@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ─── STREAMING (SSE) ─────────────────────────────────────────────────────────
+// The AI endpoint may answer with Server-Sent Events instead of one JSON body.
+// Each event is a `data:` line carrying a chunk of the message; the last one
+// is `data: [DONE]` followed by the remaining AIResponse fields as JSON.
+// The ask and research loops ask for a stream unless --no-stream is given;
+// other commands never print chunks, even if the server streams anyway
+
+// Ask the server to stream replies (disabled with --no-stream)
+var streamEnabled = true
+
+// decodeAIResponse reads an AI reply, printing it as it arrives when the
+// server sends text/event-stream and echo is set, and decoding plain JSON
+// otherwise
+func decodeAIResponse(resp *http.Response, echo bool) (*AIResponse, error) {
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return readAIStream(resp, echo)
+	}
+
+	var response AIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func readAIStream(resp *http.Response, echo bool) (*AIResponse, error) {
+	var message strings.Builder
+	var final *AIResponse
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue // comments, event names, keep-alives
+		}
+		data := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+
+		if strings.HasPrefix(data, "[DONE]") {
+			final = &AIResponse{}
+			rest := strings.TrimSpace(strings.TrimPrefix(data, "[DONE]"))
+			if rest != "" {
+				if err := json.Unmarshal([]byte(rest), final); err != nil {
+					return nil, fmt.Errorf("invalid final stream event: %v", err)
+				}
+			}
+			break
+		}
+
+		// Otherwise the full message is reported at the end instead
+		chunk := decodeStreamChunk(data)
+		if echo {
+			fmt.Print(chunk)
+		}
+		message.WriteString(chunk)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream interrupted: %v", err)
+	}
+
+	if message.Len() > 0 && echo {
+		fmt.Println()
+	}
+
+	if final == nil {
+		return nil, fmt.Errorf("stream ended before completion")
+	}
+
+	if final.Message == "" {
+		final.Message = message.String()
+	}
+	final.streamed = echo
+
+	return final, nil
+}
+
+func showStream() bool {
+	return streamEnabled && !jsonMode
+}
+
+// decodeStreamChunk extracts the text of a single data line. Chunks are
+// either JSON ({"delta": "..."}) or raw text
+func decodeStreamChunk(data string) string {
+	var chunk struct {
+		Delta string `json:"delta"`
+	}
+	if err := json.Unmarshal([]byte(data), &chunk); err == nil {
+		return chunk.Delta
+	}
+	return data
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newStreamServer answers every AI request with a two-chunk stream and
+// records the stream field of the last request
+func newStreamServer(t *testing.T) (*httptest.Server, *interface{}) {
+	t.Helper()
+	var requested interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		requested = payload["stream"]
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"delta\": \"Hello \"}\n\n")
+		fmt.Fprint(w, "data: {\"delta\": \"world\"}\n\n")
+		fmt.Fprint(w, "data: [DONE] {\"credits_used\": 1}\n\n")
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("KEKE_API_BASE_URL", server.URL)
+	loadedConfig = nil
+	return server, &requested
+}
+
+func TestPostAIStreamsOnlyWhenAsked(t *testing.T) {
+	newTestProject(t)
+	_, requested := newStreamServer(t)
+	auth := &AuthData{AccessToken: "token"}
+
+	for _, stream := range []bool{false, true} {
+		payload := map[string]interface{}{"conversation": []map[string]string{}, "model": "test"}
+		response, err := postAI(payload, auth, stream)
+		if err != nil {
+			t.Fatalf("postAI(stream=%v): %v", stream, err)
+		}
+		if *requested != stream {
+			t.Errorf("postAI(stream=%v) sent stream=%v", stream, *requested)
+		}
+		if response.streamed != stream {
+			t.Errorf("postAI(stream=%v) reported streamed=%v", stream, response.streamed)
+		}
+		if response.Message != "Hello world" {
+			t.Errorf("postAI(stream=%v) message = %q, want %q", stream, response.Message, "Hello world")
+		}
+	}
+}
+
+func TestAIClientOutlastsTimeoutWhileStreaming(t *testing.T) {
+	newTestProject(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"delta\": \"slow \"}\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(1500 * time.Millisecond)
+		fmt.Fprint(w, "data: {\"delta\": \"reply\"}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	httpTimeoutFlag = 1
+	defer func() { httpTimeoutFlag = 0 }()
+	initHTTP()
+
+	resp, err := aiClient().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	response, err := decodeAIResponse(resp, false)
+	if err != nil {
+		t.Fatalf("stream longer than the AI timeout failed: %v", err)
+	}
+	if response.Message != "slow reply" {
+		t.Errorf("message = %q, want %q", response.Message, "slow reply")
+	}
+}
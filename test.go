@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ─── TEST ────────────────────────────────────────────────────────────────────
+// 'keke test' runs the project's test suite and, when it fails, hands the
+// output to the AI to fix before running it again
+
+// Test runners, detected by a marker file in the project root
+var testRunners = []struct {
+	marker  string
+	command string
+}{
+	{"go.mod", "go test ./..."},
+	{"Cargo.toml", "cargo test"},
+	{"package.json", "npm test"},
+	{"pytest.ini", "pytest"},
+	{"pyproject.toml", "pytest"},
+	{"setup.py", "pytest"},
+	{"requirements.txt", "pytest"},
+	{"Makefile", "make test"},
+}
+
+func handleTest(args []string) {
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	if !isProjectInitialized() {
+		logError("Project not initialized. Run 'keke init'")
+		exitCode = exitNotInitialized
+		return
+	}
+
+	command := ""
+	attempts := 3
+	model := getConfig().DefaultModel
+
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--command":
+			if i+1 >= len(args) {
+				logError("--command needs a test command")
+				return
+			}
+			command = args[i+1]
+			i++
+		case "--attempts":
+			if i+1 >= len(args) {
+				logError("--attempts needs a number")
+				return
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 0 {
+				logError(fmt.Sprintf("Invalid --attempts: %s", args[i+1]))
+				return
+			}
+			attempts = n
+			i++
+		case "--fast", "--smart", "--deep":
+			model = strings.TrimPrefix(args[i], "--")
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke test [--command \"go test ./...\"] [--attempts N]")
+			return
+		}
+	}
+
+	if command == "" {
+		command = detectTestCommand()
+		if command == "" {
+			logError("Could not detect how to run tests. Use --command \"<test command>\"")
+			return
+		}
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	session := newSession("ask", model)
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		result := handleExecuteCommand(Action{Type: "execute_command", Command: command})
+		elapsed := time.Since(start)
+
+		if result == "Permission denied by user" || strings.HasPrefix(result, "Refused:") || dryRun {
+			return
+		}
+
+		if !strings.HasPrefix(result, "Command failed:") && !strings.HasPrefix(result, "Command timed out") {
+			printDivider()
+			logSuccess(fmt.Sprintf("Tests passed: %s in %s", countTests(result), elapsed.Round(time.Millisecond)))
+			return
+		}
+
+		if attempt >= attempts {
+			printDivider()
+			logError(fmt.Sprintf("Tests still failing after %d fix attempts", attempts))
+			printMessage(truncateToolOutput(result))
+			exitCode = exitError
+			return
+		}
+
+		logWarning(fmt.Sprintf("Tests failed, asking AI to fix them (attempt %d/%d)", attempt+1, attempts))
+		prompt := fmt.Sprintf("Fix these test failures.\n\nCommand: %s\n%s", command, truncateToolOutput(result))
+		conversationLoop(session, prompt, model, auth)
+	}
+}
+
+// detectTestCommand picks a test command from the files in the project root
+func detectTestCommand() string {
+	for _, runner := range testRunners {
+		if _, err := os.Stat(runner.marker); err == nil {
+			return runner.command
+		}
+	}
+	return ""
+}
+
+// Summary lines of common runners, tried in order
+var testCountPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`Tests:\s+(?:\d+ \w+, )*(\d+) passed`),      // jest
+	regexp.MustCompile(`test result: ok\. (\d+) passed`),           // cargo
+	regexp.MustCompile(`(\d+) passed`),                             // pytest
+	regexp.MustCompile(`(?m)^ok\s+\S+\s+(?:\d+\.\d+s|\(cached\))`), // go, one per package
+}
+
+// countTests describes how many tests passed according to the runner output
+func countTests(output string) string {
+	for i, pattern := range testCountPatterns {
+		matches := pattern.FindAllStringSubmatch(output, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		if i == len(testCountPatterns)-1 {
+			return fmt.Sprintf("%d packages ok", len(matches))
+		}
+		total := 0
+		for _, match := range matches {
+			n, _ := strconv.Atoi(match[1])
+			total += n
+		}
+		return fmt.Sprintf("%d tests", total)
+	}
+	return "all tests"
+}
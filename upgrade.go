@@ -3,24 +3,29 @@ package main
 import (
 	"archive/tar"
 	"archive/zip"
+	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strings"
+	"time"
 )
 
 // GitHub config - REPLACE WITH YOUR VALUES
 const (
 	githubOwner = "Aimable2002"
 	githubRepo  = "keke_aia"
-	apiURL      = "https://api.github.com/repos/" + githubOwner + "/" + githubRepo + "/releases/latest"
+	releasesURL = "https://api.github.com/repos/" + githubOwner + "/" + githubRepo + "/releases"
+	apiURL      = releasesURL + "/latest"
 )
 
 type githubAsset struct {
@@ -33,52 +38,143 @@ type githubRelease struct {
 	Assets  []githubAsset `json:"assets"`
 }
 
-func handleUpgrade() {
-	logInfo("Checking for updates...")
+// Exit code of 'keke upgrade --check' when a newer release exists
+const exitUpdateAvailable = 10
+
+func handleUpgrade(args []string) {
+	check, rollback := false, false
+	target := ""  // --version: install this tag instead of the latest
+	channel := "" // --channel: stable, beta or nightly, saved for next time
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--check":
+			check = true
+		case args[i] == "--rollback":
+			rollback = true
+		case args[i] == "--version" && i+1 < len(args):
+			target = args[i+1]
+			if !strings.HasPrefix(target, "v") {
+				target = "v" + target
+			}
+			i++
+		case args[i] == "--channel" && i+1 < len(args):
+			channel = args[i+1]
+			i++
+		default:
+			logError(fmt.Sprintf("Unknown argument: %s", args[i]))
+			logInfo("Usage: keke upgrade [--check | --version v0.1.3 | --rollback] [--channel stable|beta|nightly]")
+			exitCode = exitError
+			return
+		}
+	}
 
-	// Get latest release from GitHub
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		logError(fmt.Sprintf("Failed to check for updates: %v", err))
+	if check && target != "" {
+		logError("--check and --version cannot be combined")
+		exitCode = exitError
 		return
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		logError(fmt.Sprintf("GitHub API returned status %d", resp.StatusCode))
+	if rollback {
+		if check || target != "" || channel != "" {
+			logError("--rollback cannot be combined with other options")
+			exitCode = exitError
+			return
+		}
+		handleUpgradeRollback()
 		return
 	}
 
-	var release githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		logError(fmt.Sprintf("Failed to parse release info: %v", err))
+	if channel != "" {
+		if !validUpdateChannel(channel) {
+			logError(fmt.Sprintf("Unknown channel '%s'. Use stable, beta or nightly", channel))
+			exitCode = exitError
+			return
+		}
+		if getConfig().UpdateChannel != channel {
+			if err := saveConfigValue("update_channel", channel); err != nil {
+				logWarning(fmt.Sprintf("Failed to save update channel: %v", err))
+			} else if !jsonMode {
+				logInfo(fmt.Sprintf("Update channel set to %s", channel))
+			}
+		}
+	} else {
+		channel = getConfig().UpdateChannel
+		if channel == "" {
+			channel = "stable"
+		}
+	}
+
+	if target != "" {
+		logInfo(fmt.Sprintf("Looking up release %s...", target))
+	} else if !jsonMode {
+		logInfo(fmt.Sprintf("Checking for updates (%s channel)...", channel))
+	}
+
+	release, err := fetchRelease(channel, target)
+	if err != nil {
+		logError(err.Error())
+		exitCode = requestExitCode(err)
 		return
 	}
 
 	latestVersion := release.TagName
 	currentVersion := version
+	assetName := getAssetName()
 
-	if latestVersion == currentVersion {
-		logSuccess(fmt.Sprintf("Already up to date (%s)", currentVersion))
+	// The nightly tag is rebuilt in place, so its name never changes: it is
+	// current when its archive checksum matches the one installed last
+	upToDate := latestVersion == currentVersion
+	nightlyChecksum := ""
+	if latestVersion == nightlyTag {
+		nightlyChecksum, err = releaseChecksum(release, assetName)
+		if err != nil || nightlyChecksum == "" {
+			logError(fmt.Sprintf("No checksum for %s in the nightly build", assetName))
+			exitCode = exitError
+			return
+		}
+		upToDate = nightlyChecksum == installedNightlyChecksum()
+	}
+
+	// --check only reports, for scripts: exit 0 when current, 10 otherwise
+	if check {
+		available := !upToDate
+		if available {
+			exitCode = exitUpdateAvailable
+		}
+		if jsonMode {
+			emitJSON(map[string]interface{}{
+				"current":          currentVersion,
+				"latest":           latestVersion,
+				"update_available": available,
+			})
+		} else if available {
+			fmt.Printf("Update available: %s -> %s\n", currentVersion, latestVersion)
+		} else {
+			fmt.Println("Up to date")
+		}
+		return
+	}
+
+	if upToDate {
+		if target != "" {
+			logSuccess(fmt.Sprintf("Already on %s", currentVersion))
+		} else {
+			logSuccess(fmt.Sprintf("Already up to date (%s)", currentVersion))
+		}
 		return
 	}
 
-	logInfo(fmt.Sprintf("Upgrading %s → %s", currentVersion, latestVersion))
+	if versionPattern.MatchString(latestVersion) && compareVersions(latestVersion, currentVersion) < 0 {
+		logWarning(fmt.Sprintf("Downgrading %s → %s. Newer fixes will be lost; run 'keke upgrade' to return to the latest", currentVersion, latestVersion))
+	} else {
+		logInfo(fmt.Sprintf("Upgrading %s → %s", currentVersion, latestVersion))
+	}
 
 	// Find correct binary for this OS/arch
-	assetName := getAssetName()
-	checksumName := "keke_checksums.txt"
-
 	var downloadURL string
-	var checksumURL string
-
 	for _, asset := range release.Assets {
 		if asset.Name == assetName {
 			downloadURL = asset.BrowserDownloadURL
 		}
-		if asset.Name == checksumName {
-			checksumURL = asset.BrowserDownloadURL
-		}
 	}
 
 	if downloadURL == "" {
@@ -86,23 +182,26 @@ func handleUpgrade() {
 		return
 	}
 
-	// Download checksum
-	var expectedChecksum string
-	if checksumURL != "" {
-		logInfo("Downloading checksum...")
-		checksumData, err := downloadFile(checksumURL)
-		if err != nil {
+	// Download checksum (already done for a nightly)
+	expectedChecksum := nightlyChecksum
+	if expectedChecksum == "" {
+		if expectedChecksum, err = releaseChecksum(release, assetName); err != nil {
 			logWarning("Failed to download checksum, skipping verification")
-		} else {
-			expectedChecksum = parseChecksum(string(checksumData), assetName)
 		}
 	}
 
+	// Pre-release builds are only installed when they can be verified
+	if expectedChecksum == "" && target == "" && channel != "stable" {
+		logError(fmt.Sprintf("No checksum for %s in %s. Aborting", assetName, latestVersion))
+		exitCode = exitError
+		return
+	}
+
 	// Download binary archive
 	logInfo("Downloading binary...")
 	archiveData, err := downloadFile(downloadURL)
 	if err != nil {
-		logError(fmt.Sprintf("Failed to download: %v", err))
+		logRequestError("Failed to download", err)
 		return
 	}
 
@@ -134,15 +233,284 @@ func handleUpgrade() {
 	}
 	execPath, _ = filepath.EvalSymlinks(execPath)
 
-	// Replace binary
-	if err := os.WriteFile(execPath, binaryData, 0755); err != nil {
+	// Replace binary, only once the new one has proven it runs
+	logInfo("Verifying new binary...")
+	if err := installBinary(execPath, binaryData); err != nil {
 		logError(fmt.Sprintf("Failed to replace binary: %v", err))
-		logWarning("You may need to run with sudo/admin privileges")
+		logWarning(fmt.Sprintf("Kept %s unchanged. You may need to run with sudo/admin privileges", currentVersion))
+		exitCode = exitError
 		return
 	}
 
+	if err := recordNightlyChecksum(nightlyChecksum); err != nil {
+		logWarning(fmt.Sprintf("Failed to record the installed nightly: %v", err))
+	}
+
 	logSuccess(fmt.Sprintf("Upgraded to %s", latestVersion))
-	logInfo("Run 'keke version' to confirm")
+	logInfo("Run 'keke version' to confirm, or 'keke upgrade --rollback' to return to " + currentVersion)
+}
+
+// Tag of the rolling nightly release, and the checksums file of a release
+const (
+	nightlyTag        = "nightly"
+	checksumAssetName = "keke_checksums.txt"
+)
+
+// releaseChecksum downloads the checksums file of release and returns the
+// one for assetName, or "" when the release has none
+func releaseChecksum(release *githubRelease, assetName string) (string, error) {
+	for _, asset := range release.Assets {
+		if asset.Name == checksumAssetName {
+			logInfo("Downloading checksum...")
+			data, err := downloadFile(asset.BrowserDownloadURL)
+			if err != nil {
+				return "", err
+			}
+			return parseChecksum(string(data), assetName), nil
+		}
+	}
+	return "", nil
+}
+
+// ~/.keke/nightly.sha256 holds the archive checksum of the installed nightly
+func nightlyChecksumFile() string {
+	return filepath.Join(globalDir(), "nightly.sha256")
+}
+
+func installedNightlyChecksum() string {
+	data, err := os.ReadFile(nightlyChecksumFile())
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// recordNightlyChecksum remembers the nightly just installed, or forgets it
+// when checksum is "" (a tagged release or a rollback replaced it)
+func recordNightlyChecksum(checksum string) error {
+	if checksum == "" {
+		if err := os.Remove(nightlyChecksumFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(globalDir(), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(nightlyChecksumFile(), []byte(checksum+"\n"), 0644)
+}
+
+// handleUpgradeRollback reinstalls the binary the last upgrade replaced.
+// That puts the current one in .prev, so a second rollback undoes the first
+func handleUpgradeRollback() {
+	execPath, err := os.Executable()
+	if err != nil {
+		logError(fmt.Sprintf("Cannot determine binary path: %v", err))
+		return
+	}
+	execPath, _ = filepath.EvalSymlinks(execPath)
+	prevPath := execPath + ".prev"
+
+	data, err := os.ReadFile(prevPath)
+	if os.IsNotExist(err) {
+		logError("No previous version to roll back to")
+		logInfo(fmt.Sprintf("%s is saved by 'keke upgrade'", prevPath))
+		exitCode = exitError
+		return
+	}
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read %s: %v", prevPath, err))
+		exitCode = exitError
+		return
+	}
+
+	prevVersion, err := binaryVersion(prevPath)
+	if err != nil {
+		logError(fmt.Sprintf("The saved binary does not run: %v", err))
+		exitCode = exitError
+		return
+	}
+	// Two nightlies share a version, so only an identical binary is refused
+	if current, err := os.ReadFile(execPath); err == nil && bytes.Equal(current, data) {
+		logError(fmt.Sprintf("The saved binary is the one installed (%s); nothing to roll back", version))
+		exitCode = exitError
+		return
+	}
+
+	logInfo(fmt.Sprintf("Rolling back %s → %s", version, prevVersion))
+	if err := installBinary(execPath, data); err != nil {
+		logError(fmt.Sprintf("Failed to restore binary: %v", err))
+		logWarning(fmt.Sprintf("Kept %s unchanged. You may need to run with sudo/admin privileges", version))
+		exitCode = exitError
+		return
+	}
+	if err := recordNightlyChecksum(""); err != nil {
+		logWarning(fmt.Sprintf("Failed to forget the replaced nightly: %v", err))
+	}
+	logSuccess(fmt.Sprintf("Restored %s", prevVersion))
+}
+
+// Update channels: stable follows /releases/latest, beta the newest -beta or
+// -rc tag, nightly the rolling 'nightly' tag
+var updateChannels = []string{"stable", "beta", "nightly"}
+
+func validUpdateChannel(channel string) bool {
+	for _, c := range updateChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRelease looks up the release to install: the tag when one is given,
+// otherwise the newest release on channel
+func fetchRelease(channel, tag string) (*githubRelease, error) {
+	url := apiURL
+	switch {
+	case tag != "":
+		url = releasesURL + "/tags/" + tag
+	case channel == "beta":
+		url = releasesURL
+	case channel == "nightly":
+		url = releasesURL + "/tags/" + nightlyTag
+	}
+
+	resp, err := httpClient().Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to check for updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		switch {
+		case tag != "":
+			return nil, fmt.Errorf("Release %s not found", tag)
+		case channel == "nightly":
+			return nil, fmt.Errorf("No nightly build has been published")
+		}
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	if tag != "" || channel != "beta" {
+		var release githubRelease
+		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+			return nil, fmt.Errorf("Failed to parse release info: %v", err)
+		}
+		return &release, nil
+	}
+
+	// GitHub lists releases newest first
+	var releases []githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("Failed to parse release info: %v", err)
+	}
+	for i := range releases {
+		if isBetaTag(releases[i].TagName) {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("No beta release has been published")
+}
+
+// isBetaTag reports whether tag is a pre-release such as v0.2.0-beta.1 or v0.2.0-rc1
+func isBetaTag(tag string) bool {
+	_, suffix, ok := strings.Cut(tag, "-")
+	return ok && (strings.HasPrefix(suffix, "beta") || strings.HasPrefix(suffix, "rc"))
+}
+
+// installBinary writes data to a temp file next to execPath, checks that it
+// runs and reports a version, copies the current binary to execPath.prev for
+// 'keke upgrade --rollback', then renames the new one over execPath. The
+// original is left untouched on any failure
+func installBinary(execPath string, data []byte) error {
+	pattern := ".keke-upgrade-*"
+	if runtime.GOOS == "windows" {
+		pattern += ".exe"
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(execPath), pattern)
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return err
+	}
+
+	if err := verifyBinary(tmpPath); err != nil {
+		return fmt.Errorf("new binary does not run: %v", err)
+	}
+	if err := backupBinary(execPath); err != nil {
+		return fmt.Errorf("failed to save the current binary: %v", err)
+	}
+
+	// Windows can't replace a running executable, but it can move it aside
+	if runtime.GOOS == "windows" {
+		old := execPath + ".old"
+		os.Remove(old)
+		if err := os.Rename(execPath, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, execPath); err != nil {
+			os.Rename(old, execPath)
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmpPath, execPath)
+}
+
+var versionPattern = regexp.MustCompile(`^v?\d+\.\d+`)
+
+// backupBinary copies execPath to execPath.prev, through a temp file so a
+// failed copy never leaves a truncated backup
+func backupBinary(execPath string) error {
+	data, err := os.ReadFile(execPath)
+	if err != nil {
+		return err
+	}
+	tmpPath := execPath + ".prev.tmp"
+	if err := os.WriteFile(tmpPath, data, 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, execPath+".prev")
+}
+
+// verifyBinary checks that path runs and prints a version string
+func verifyBinary(path string) error {
+	printed, err := binaryVersion(path)
+	if err != nil {
+		return err
+	}
+	logSuccess(fmt.Sprintf("New binary reports %s", printed))
+	return nil
+}
+
+// binaryVersion runs 'path --version' and returns the version it prints
+func binaryVersion(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	printed := strings.TrimSpace(string(out))
+	if !versionPattern.MatchString(printed) {
+		return "", fmt.Errorf("unexpected version output %q", firstLine(printed))
+	}
+	return printed, nil
 }
 
 func getAssetName() string {
@@ -157,8 +525,18 @@ func getAssetName() string {
 	return fmt.Sprintf("keke_%s_%s%s", osName, arch, ext)
 }
 
+// downloadFile fetches url, with a progress bar when stderr is a terminal
 func downloadFile(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+	if !isTerminal(os.Stderr) {
+		return downloadWithProgress(url, nil)
+	}
+	return downloadWithProgress(url, os.Stderr)
+}
+
+// downloadWithProgress fetches url into memory, drawing a progress bar on w
+// as it arrives (nil w draws nothing). The bar is cleared when done
+func downloadWithProgress(url string, w io.Writer) ([]byte, error) {
+	resp, err := downloadClient().Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +546,61 @@ func downloadFile(url string) ([]byte, error) {
 		return nil, fmt.Errorf("download failed with status %d", resp.StatusCode)
 	}
 
-	return io.ReadAll(resp.Body)
+	if w == nil {
+		return io.ReadAll(resp.Body)
+	}
+
+	progress := &progressReader{r: resp.Body, w: w, total: resp.ContentLength}
+	data, err := io.ReadAll(progress)
+	progress.clear()
+	return data, err
+}
+
+// progressReader draws a progress bar on w as a download arrives, or the
+// bytes received when the size is unknown (total < 0, chunked transfer)
+type progressReader struct {
+	r     io.Reader
+	w     io.Writer
+	total int64
+	read  int64
+	drawn time.Time
+	width int // length of the last line drawn, so it can be blanked
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if time.Since(p.drawn) >= 100*time.Millisecond {
+		p.draw()
+	}
+	return n, err
+}
+
+// draw rewrites the current line, e.g. [=====     ] 52% (5.2 MB / 10.0 MB)
+func (p *progressReader) draw() {
+	p.drawn = time.Now()
+	var line string
+	if p.total > 0 && p.read <= p.total {
+		const width = 30
+		filled := int(p.read * width / p.total)
+		line = fmt.Sprintf("  [%s%s] %3d%% (%s / %s)", strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+			p.read*100/p.total, formatBytes(p.read), formatBytes(p.total))
+	} else {
+		line = fmt.Sprintf("  Downloaded %s", formatBytes(p.read))
+	}
+	pad := ""
+	if len(line) < p.width {
+		pad = strings.Repeat(" ", p.width-len(line))
+	}
+	fmt.Fprintf(p.w, "\r%s%s", line, pad)
+	p.width = len(line)
+}
+
+// clear blanks the progress line, leaving the cursor at its start
+func (p *progressReader) clear() {
+	if p.width > 0 {
+		fmt.Fprintf(p.w, "\r%s\r", strings.Repeat(" ", p.width))
+	}
 }
 
 func parseChecksum(checksumFile, filename string) string {
@@ -195,27 +627,31 @@ func extractBinary(archiveData []byte, archiveName string) ([]byte, error) {
 }
 
 func extractTarGz(data []byte) ([]byte, error) {
-	// Write to temp file
-	tmpFile, err := os.CreateTemp("", "keke-*.tar.gz")
+	var binary []byte
+	err := eachTarGzFile(data, func(header *tar.Header, r io.Reader) (bool, error) {
+		base := filepath.Base(header.Name)
+		if base != "keke" && base != "keke.exe" {
+			return false, nil
+		}
+		var err error
+		binary, err = io.ReadAll(r)
+		return true, err
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpFile.Name())
-
-	if _, err := tmpFile.Write(data); err != nil {
-		return nil, err
-	}
-	tmpFile.Close()
-
-	f, err := os.Open(tmpFile.Name())
-	if err != nil {
-		return nil, err
+	if binary == nil {
+		return nil, fmt.Errorf("keke binary not found in archive")
 	}
-	defer f.Close()
+	return binary, nil
+}
 
-	gzr, err := gzip.NewReader(f)
+// eachTarGzFile calls fn with every regular file in a .tar.gz archive until
+// fn asks to stop or fails
+func eachTarGzFile(data []byte, fn func(header *tar.Header, r io.Reader) (stop bool, err error)) error {
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer gzr.Close()
 
@@ -223,19 +659,19 @@ func extractTarGz(data []byte) ([]byte, error) {
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return nil, err
+			return err
 		}
-
-		name := filepath.Base(header.Name)
-		if name == "keke" || name == "keke.exe" {
-			return io.ReadAll(tr)
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		stop, err := fn(header, tr)
+		if err != nil || stop {
+			return err
 		}
 	}
-
-	return nil, fmt.Errorf("keke binary not found in archive")
 }
 
 func extractZip(data []byte) ([]byte, error) {
@@ -269,4 +705,29 @@ func extractZip(data []byte) ([]byte, error) {
 	}
 
 	return nil, fmt.Errorf("keke binary not found in zip")
-}
\ No newline at end of file
+}
+
+// compareVersions orders tags like v0.1.3 numerically: -1 when a is older
+// than b, 1 when newer, 0 when equal. Pre-release suffixes are ignored
+func compareVersions(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, _, _ = strings.Cut(strings.TrimPrefix(b, "v"), "-")
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			fmt.Sscanf(pa[i], "%d", &na)
+		}
+		if i < len(pb) {
+			fmt.Sscanf(pb[i], "%d", &nb)
+		}
+		if na != nb {
+			if na < nb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
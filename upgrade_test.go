@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNightlyChecksum(t *testing.T) {
+	newTestProject(t)
+	checksums := "aaa111  keke_linux_amd64.tar.gz\nbbb222  keke_darwin_arm64.tar.gz\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, checksums)
+	}))
+	defer server.Close()
+
+	release := &githubRelease{TagName: nightlyTag, Assets: []githubAsset{
+		{Name: "keke_linux_amd64.tar.gz", BrowserDownloadURL: server.URL + "/keke_linux_amd64.tar.gz"},
+		{Name: checksumAssetName, BrowserDownloadURL: server.URL + "/" + checksumAssetName},
+	}}
+	checksum, err := releaseChecksum(release, "keke_linux_amd64.tar.gz")
+	if err != nil || checksum != "aaa111" {
+		t.Fatalf("releaseChecksum = %q, %v, want aaa111", checksum, err)
+	}
+
+	// Nothing recorded: the nightly is an update
+	if got := installedNightlyChecksum(); got != "" {
+		t.Fatalf("installed nightly = %q before any install", got)
+	}
+	if err := recordNightlyChecksum(checksum); err != nil {
+		t.Fatal(err)
+	}
+	if got := installedNightlyChecksum(); got != checksum {
+		t.Errorf("installed nightly = %q, want %q", got, checksum)
+	}
+
+	// A rebuilt nightly has a new checksum under the same tag
+	checksums = "ccc333  keke_linux_amd64.tar.gz\n"
+	if rebuilt, _ := releaseChecksum(release, "keke_linux_amd64.tar.gz"); rebuilt == installedNightlyChecksum() {
+		t.Errorf("rebuilt nightly %q looks installed", rebuilt)
+	}
+
+	if err := recordNightlyChecksum(""); err != nil {
+		t.Fatal(err)
+	}
+	if got := installedNightlyChecksum(); got != "" {
+		t.Errorf("installed nightly = %q after forgetting it", got)
+	}
+}
+
+func TestReleaseChecksumWithoutChecksumsFile(t *testing.T) {
+	release := &githubRelease{TagName: "v0.2.0", Assets: []githubAsset{{Name: "keke_linux_amd64.tar.gz"}}}
+	if checksum, err := releaseChecksum(release, "keke_linux_amd64.tar.gz"); checksum != "" || err != nil {
+		t.Errorf("releaseChecksum = %q, %v, want none", checksum, err)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ─── SIGNAL WATCHLIST ────────────────────────────────────────────────────────
+// Symbols saved in ~/.keke/watchlist.json so 'keke signal watch run' can
+// fetch all of them in one go
+
+// WatchItem - one watched symbol
+type WatchItem struct {
+	Symbol    string `json:"symbol"`
+	Timeframe string `json:"timeframe"`
+	Provider  string `json:"provider,omitempty"`
+}
+
+func readWatchlist() ([]WatchItem, error) {
+	data, err := os.ReadFile(globalWatchlistFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var items []WatchItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("corrupt watchlist: %v", err)
+	}
+	return items, nil
+}
+
+func writeWatchlist(items []WatchItem) error {
+	if err := os.MkdirAll(globalDir(), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(globalWatchlistFile(), data, 0600)
+}
+
+func handleSignalWatch(args []string) {
+	if len(args) == 0 {
+		printWatchUsage()
+		return
+	}
+
+	switch args[0] {
+	case "add":
+		watchAdd(args[1:])
+	case "remove":
+		watchRemove(args[1:])
+	case "list":
+		watchList()
+	case "run":
+		watchRun(args[1:])
+	default:
+		logError(fmt.Sprintf("Unknown subcommand: %s", args[0]))
+		printWatchUsage()
+	}
+}
+
+func printWatchUsage() {
+	logInfo("Usage:")
+	logInfo("  keke signal watch add <SYMBOL> [--timeframe 1H|4H|1D] [--provider P]")
+	logInfo("  keke signal watch remove <SYMBOL>")
+	logInfo("  keke signal watch list")
+	logInfo("  keke signal watch run [--alert EMAIL] [--alert-threshold 75]")
+}
+
+func watchAdd(args []string) {
+	if len(args) == 0 {
+		logError("Usage: keke signal watch add <SYMBOL> [--timeframe 1H|4H|1D] [--provider P]")
+		return
+	}
+
+	// --provider (a global flag) is remembered for this pair
+	item := WatchItem{Symbol: strings.ToUpper(args[0]), Timeframe: getConfig().DefaultTimeframe, Provider: providerFlag}
+	for i := 1; i < len(args); i++ {
+		if args[i] == "--timeframe" && i+1 < len(args) {
+			item.Timeframe = args[i+1]
+			i++
+		}
+	}
+
+	if err := validateSymbol(item.Symbol); err != nil {
+		logError(err.Error())
+		return
+	}
+	timeframe, err := normalizeTimeframe(item.Timeframe)
+	if err != nil {
+		logError(err.Error())
+		return
+	}
+	item.Timeframe = timeframe
+
+	items, err := readWatchlist()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read watchlist: %v", err))
+		return
+	}
+
+	// Re-adding a symbol updates its timeframe and provider
+	replaced := false
+	for i := range items {
+		if items[i].Symbol == item.Symbol {
+			items[i] = item
+			replaced = true
+		}
+	}
+	if !replaced {
+		items = append(items, item)
+	}
+
+	if err := writeWatchlist(items); err != nil {
+		logError(fmt.Sprintf("Failed to save watchlist: %v", err))
+		return
+	}
+
+	if replaced {
+		logSuccess(fmt.Sprintf("Updated %s (%s)", item.Symbol, item.Timeframe))
+	} else {
+		logSuccess(fmt.Sprintf("Watching %s (%s)", item.Symbol, item.Timeframe))
+	}
+}
+
+func watchRemove(args []string) {
+	if len(args) == 0 {
+		logError("Usage: keke signal watch remove <SYMBOL>")
+		return
+	}
+	symbol := strings.ToUpper(args[0])
+
+	items, err := readWatchlist()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read watchlist: %v", err))
+		return
+	}
+
+	var kept []WatchItem
+	for _, item := range items {
+		if item.Symbol != symbol {
+			kept = append(kept, item)
+		}
+	}
+	if len(kept) == len(items) {
+		logError(fmt.Sprintf("%s is not on the watchlist", symbol))
+		return
+	}
+
+	if err := writeWatchlist(kept); err != nil {
+		logError(fmt.Sprintf("Failed to save watchlist: %v", err))
+		return
+	}
+	logSuccess(fmt.Sprintf("Removed %s", symbol))
+}
+
+func watchList() {
+	items, err := readWatchlist()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read watchlist: %v", err))
+		return
+	}
+
+	if jsonMode {
+		emitJSON(items)
+		return
+	}
+
+	if len(items) == 0 {
+		logInfo("Watchlist is empty. Add one with: keke signal watch add EURUSD")
+		return
+	}
+
+	printDivider()
+	for _, item := range items {
+		provider := ""
+		if item.Provider != "" {
+			provider = fmt.Sprintf(" %s(%s)%s", dim, item.Provider, reset)
+		}
+		fmt.Printf("  %s•%s %-10s %s%s\n", cyan, reset, item.Symbol, item.Timeframe, provider)
+	}
+	printDivider()
+}
+
+// watchRun fetches a signal for every watched symbol and prints a summary
+// table. It takes the same --alert flags as 'keke signal'
+func watchRun(args []string) {
+	alert := &alertOptions{Threshold: defaultAlertThreshold}
+	for i := 0; i < len(args); i++ {
+		n, err := parseAlertFlag(args, i, alert)
+		if err == nil && n == 0 {
+			err = fmt.Errorf("Unknown argument: %s", args[i])
+		}
+		if err != nil {
+			logError(err.Error())
+			logInfo("Usage: keke signal watch run [--alert EMAIL] [--alert-threshold 75]")
+			return
+		}
+		i += n - 1
+	}
+
+	if !isLoggedIn() {
+		logError("Not logged in. Run 'keke login'")
+		exitCode = exitNotLoggedIn
+		return
+	}
+
+	items, err := readWatchlist()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read watchlist: %v", err))
+		return
+	}
+	if len(items) == 0 {
+		logInfo("Watchlist is empty. Add one with: keke signal watch add EURUSD")
+		return
+	}
+
+	auth, err := readAuth()
+	if err != nil {
+		logError(fmt.Sprintf("Failed to read auth: %v", err))
+		return
+	}
+
+	// Watched items without a provider use --provider or the configured default
+	for i := range items {
+		if items[i].Provider == "" {
+			items[i].Provider = aiProvider()
+		}
+	}
+
+	retryPendingAlerts(auth)
+	runSignalBatch(items, false, "", alert, auth)
+}